@@ -0,0 +1,74 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashibuto/artillery/pkg/tg"
+)
+
+func makeAliasCommand() *Command {
+	return &Command{
+		Name:        "alias",
+		Description: "define aliases, or list them when no definition is given",
+		Arguments: []*Argument{
+			{
+				Name:        "definition",
+				Description: "definition in the form of name=expansion, or the name of an alias to display",
+				IsArray:     true,
+				CompletionFunc: func(prefix string, processor *Processor) []string {
+					names := []string{}
+					for _, name := range processor.aliasNames() {
+						if strings.HasPrefix(name, prefix) {
+							names = append(names, name)
+						}
+					}
+					return names
+				},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			var args struct {
+				Definition []string
+			}
+			err := Reflect(ns, &args)
+			if err != nil {
+				return err
+			}
+
+			table := tg.NewTable("alias", "expansion")
+			table.HideHeading = true
+			if len(args.Definition) == 0 {
+				for _, name := range processor.aliasNames() {
+					expansion, _ := processor.Alias(name)
+					table.Append(name, expansion)
+				}
+				table.RenderTo(processor.Stdout())
+				return nil
+			}
+
+			shown := false
+			for _, definition := range args.Definition {
+				name, expansion, ok := strings.Cut(definition, "=")
+				if !ok {
+					expansion, exists := processor.Alias(definition)
+					if !exists {
+						return fmt.Errorf("Alias \"%s\" not found", definition)
+					}
+					table.Append(definition, expansion)
+					shown = true
+					continue
+				}
+				err = processor.AddAlias(name, expansion)
+				if err != nil {
+					return err
+				}
+			}
+
+			if shown {
+				table.RenderTo(processor.Stdout())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package artillery
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var validAliasName = regexp.MustCompile("^[A-Za-z0-9_][A-Za-z0-9_.-]*$")
+
+// AddAlias defines an alias which is replaced by its expansion whenever it appears as the name of a command,
+// ie. AddAlias("ll", "list --long").  An existing alias of the same name is replaced.
+func (p *Processor) AddAlias(name string, expansion string) error {
+	if !validAliasName.MatchString(name) {
+		return fmt.Errorf("Invalid alias name \"%s\", names may only contain A-Z, a-z, 0-9, _, . and - and cannot begin with . or -", name)
+	}
+	lexed := lex(expansion)
+	if lexed.openQuote || lexed.openEscape {
+		return fmt.Errorf("Alias \"%s\" has an incomplete expansion", name)
+	}
+
+	if p.aliases == nil {
+		p.aliases = newVariableStore()
+	}
+	p.aliases.set(name, expansion)
+	return nil
+}
+
+// RemoveAlias removes a previously defined alias
+func (p *Processor) RemoveAlias(name string) error {
+	if p.aliases == nil || !p.aliases.remove(name) {
+		return fmt.Errorf("Alias \"%s\" not found", name)
+	}
+	return nil
+}
+
+// Alias returns the expansion of the named alias
+func (p *Processor) Alias(name string) (string, bool) {
+	if p.aliases == nil {
+		return "", false
+	}
+	return p.aliases.get(name)
+}
+
+// aliasNames returns the sorted names of all aliases
+func (p *Processor) aliasNames() []string {
+	if p.aliases == nil {
+		return []string{}
+	}
+	return p.aliases.names()
+}
+
+// aliasWord returns the alias named by the word, which must be entirely unquoted in order to be expanded
+func (p *Processor) aliasWord(w *word) (string, string, bool) {
+	if w.operator != "" || len(w.parts) != 1 || w.parts[0].quoting != unquoted {
+		return "", "", false
+	}
+	expansion, ok := p.Alias(w.parts[0].text)
+	return w.parts[0].text, expansion, ok
+}
+
+// expandAliases replaces each word in command position which names an alias with the words of its
+// expansion.  An alias is never expanded within its own expansion, which prevents endless recursion
+// while still permitting aliases such as ls="ls -l".
+func (p *Processor) expandAliases(words []*word, active map[string]bool) []*word {
+	result := []*word{}
+	commandStart := true
+	for _, w := range words {
+		if commandStart {
+			if name, expansion, ok := p.aliasWord(w); ok && !active[name] {
+				active[name] = true
+				expanded := p.expandAliases(lex(expansion).words, active)
+				delete(active, name)
+
+				result = append(result, expanded...)
+				if len(expanded) > 0 {
+					// An expansion ending in a control operator leaves the following word in command position
+					last := expanded[len(expanded)-1]
+					commandStart = last.operator != "" && !isRedirect(last.operator)
+				}
+				continue
+			}
+		}
+
+		result = append(result, w)
+		commandStart = w.operator != "" && !isRedirect(w.operator)
+	}
+
+	return result
+}
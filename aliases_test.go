@@ -0,0 +1,174 @@
+package artillery
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProcessorAliases(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		isError  bool
+	}{
+		{"one", "a", false},
+		{"two", "b,c", false},
+		{"label x", "x", false},
+		{"ok label", "label", false},
+		{"nested", "b,c,d", false},
+		{"ok x; one", "x,a", false},
+		{"ok x && label y", "x,y", false},
+		{"'one'", "", true},
+		{"self", "", true},
+		{"loop", "", true},
+	}
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "ok",
+		Description: "always succeeds",
+		Arguments: []*Argument{
+			{
+				Name:        "label",
+				Description: "label recorded on execution",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			calls = append(calls, ns["label"].(string))
+			return nil
+		},
+	})
+	processor.AddAlias("one", "ok a")
+	processor.AddAlias("two", "ok b; ok c")
+	processor.AddAlias("label", "ok")
+	processor.AddAlias("nested", "two && ok d")
+	processor.AddAlias("self", "self")
+	processor.AddAlias("loop", "pool")
+	processor.AddAlias("pool", "loop")
+
+	for _, c := range cases {
+		calls = calls[:0]
+		err := processor.onExecute(nil, c.input, true)
+		if (err != nil) != c.isError {
+			t.Errorf("Input %q expected error %v, got %v", c.input, c.isError, err)
+		}
+		if actual := strings.Join(calls, ","); actual != c.expected {
+			t.Errorf("Input %q expected calls %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestProcessorAliasBuiltins(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommand(&Command{
+		Name:        "emit",
+		Description: "writes each argument on its own line",
+		Arguments: []*Argument{
+			{
+				Name:        "lines",
+				Description: "lines to write",
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			for _, line := range ns["lines"].([]string) {
+				fmt.Fprintln(processor.Stdout(), line)
+			}
+			return nil
+		},
+	})
+	steps := []struct {
+		input    string
+		expected string
+		isError  bool
+	}{
+		{"alias hi='emit hello' bye='emit goodbye'", "", false},
+		{"hi && bye", "hello\ngoodbye\n", false},
+		{"alias hi", "emit hello", false},
+		{"unalias hi", "", false},
+		{"hi", "", true},
+		{"alias hi", "", true},
+		{"unalias -a && alias", "", false},
+		{"bye", "", true},
+	}
+
+	for _, step := range steps {
+		stdout.Reset()
+		err := processor.onExecute(nil, step.input, true)
+		if (err != nil) != step.isError {
+			t.Errorf("Input %q expected error %v, got %v", step.input, step.isError, err)
+		}
+		if output := stdout.String(); (step.expected == "" && output != "") || !strings.Contains(output, step.expected) {
+			t.Errorf("Input %q expected output %q, got %q", step.input, step.expected, stdout.String())
+		}
+	}
+}
+
+func TestProcessorAddAlias(t *testing.T) {
+	processor := NewProcessor()
+	for _, name := range []string{"", "-x", "a b", "a=b", "a;b"} {
+		if err := processor.AddAlias(name, "help"); err == nil {
+			t.Errorf("Alias name %q expected an error", name)
+		}
+	}
+	if err := processor.AddAlias("x", "help 'a"); err == nil {
+		t.Error("Expected an error for an incomplete expansion")
+	}
+	if err := processor.RemoveAlias("x"); err == nil {
+		t.Error("Expected an error removing an undefined alias")
+	}
+}
+
+func TestProcessorAliasCompletion(t *testing.T) {
+	processor := NewProcessor()
+	processor.AddCommands(
+		&Command{
+			Name:        "ok",
+			Description: "always succeeds",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return nil
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return nil
+			},
+		},
+	)
+	processor.AddAlias("okay", "ok")
+
+	sug := processor.OnComplete("ok", "", "ok")
+	if len(sug) != 2 || sug[0].Name != "ok" || sug[1].Name != "okay" {
+		t.Errorf("Expected the alias to be offered alongside the command, got %v", sug)
+	}
+
+	processor.AddAlias("h", "help")
+	sug = processor.OnComplete("h fa", "", "h fa")
+	if len(sug) != 1 || sug[0].Name != "fail" {
+		t.Errorf("Expected completion through the alias, got %v", sug)
+	}
+
+	processor.AddAlias("x", "help x")
+	sug = processor.OnComplete("x ", "", "x ")
+	if len(sug) != 0 {
+		t.Errorf("Expected no suggestions for a recursive alias, got %v", sug)
+	}
+}
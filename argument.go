@@ -2,6 +2,7 @@ package artillery
 
 import (
 	"fmt"
+	"strings"
 )
 
 type CompletionFunc func(prefix string, processor *Processor) []string
@@ -9,11 +10,21 @@ type CompletionFunc func(prefix string, processor *Processor) []string
 type Argument struct {
 	Name           string
 	Description    string
-	Type           ArgType        // String is the default argument type
-	Default        any            // Default value (only valid in the final argument position)
-	MemberOf       []string       // When value must be a member of a limited collection (strings only)
-	CompletionFunc CompletionFunc // Used to dynamically list member values, with a prefix for optimization
-	IsArray        bool           // When true, argument becomes an array (must be in the final argument position)
+	Type           ArgType               // String is the default argument type
+	Default        any                   // Default value (only valid in the final argument position)
+	MemberOf       []string              // When value must be a member of a limited collection (strings only)
+	CompletionFunc CompletionFunc        // Used to dynamically list member values, with a prefix for optimization
+	IsArray        bool                  // When true, argument becomes an array (must be in the final argument position)
+	PathCheck      PathCheck             // Checks applied to values of the path, file and dir types
+	Schemes        []string              // Schemes permitted for values of the url type, any scheme when empty
+	Min            any                   // Inclusive minimum for numeric, duration and time values
+	Max            any                   // Inclusive maximum for numeric, duration and time values
+	MinLen         int                   // Minimum length of string values
+	MaxLen         int                   // Maximum length of string values, unlimited when 0
+	Pattern        string                // Regular expression which string values must match in their entirety
+	MinCount       int                   // Minimum number of values when IsArray is true
+	MaxCount       int                   // Maximum number of values when IsArray is true, unlimited when 0
+	ValidateFunc   func(value any) error // Checks each converted value, and filters completion candidates
 }
 
 // Validate ensures the validity of the argument
@@ -40,6 +51,11 @@ func (arg *Argument) Validate(isLast bool) error {
 		return fmt.Errorf("Argument must have a description")
 	}
 
+	err := arg.constraints().validate()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -56,19 +72,7 @@ func (arg *Argument) ApplyDefault(namespace Namespace) {
 func (arg *Argument) ApplyArrayDefaults(namespace Namespace) {
 	if arg.IsArray {
 		val := namespace[arg.Name]
-		var length int
-		switch t := val.(type) {
-		case []string:
-			length = len(t)
-		case []int:
-			length = len(t)
-		case []float64:
-			length = len(t)
-		case []bool:
-			length = len(t)
-		}
-
-		if length == 0 && arg.Default != nil {
+		if arrayLength(val) == 0 && arg.Default != nil {
 			namespace[arg.Name] = arg.Default
 		}
 	}
@@ -87,24 +91,32 @@ func (arg *Argument) Usage() string {
 	return fmt.Sprintf("<%s>", arg.Name)
 }
 
+// validCompletion returns true when a completion candidate passes the ValidateFunc.  Directories are kept for
+// the path types, so that completion can continue into them.
+func (arg *Argument) validCompletion(candidate string) bool {
+	if arg.ValidateFunc == nil || (isPathType(arg.Type) && strings.HasSuffix(candidate, "/")) {
+		return true
+	}
+	val, err := convert(candidate, arg.Type)
+	return err == nil && arg.ValidateFunc(val) == nil
+}
+
 // Apply will apply the input to the target.  If input is nil then the default will be applied
 func (arg *Argument) Apply(inp string, namespace Namespace) error {
 	val, err := convert(inp, arg.Type)
+	if err == nil {
+		err = validateValue(val, arg.constraints(), arg.ValidateFunc)
+	}
 	if err != nil {
-		return fmt.Errorf("Argument %s - %w", arg.Name, err)
+		return &ValidationError{Token: inp, Name: arg.Name, Err: err, prefix: "Argument " + arg.Name}
 	}
 
 	if arg.IsArray {
-		switch t := namespace[arg.Name].(type) {
-		case []string:
-			namespace[arg.Name] = append(t, val.(string))
-		case []int:
-			namespace[arg.Name] = append(t, val.(int))
-		case []float64:
-			namespace[arg.Name] = append(t, val.(float64))
-		case []bool:
-			namespace[arg.Name] = append(t, val.(bool))
+		arr, err := appendValue(namespace[arg.Name], val)
+		if err != nil {
+			return fmt.Errorf("Argument %s - %s", arg.Name, err)
 		}
+		namespace[arg.Name] = arr
 		return nil
 	}
 
@@ -0,0 +1,163 @@
+package artillery
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// byteUnits are the multipliers accepted by the Bytes type, SI units are powers of 1000 and IEC units (ie. MiB)
+// are powers of 1024.  Units are matched without regard to case, and the trailing B is optional.
+var byteUnits = []struct {
+	si  string
+	iec string
+	exp int
+}{
+	{"kB", "KiB", 1},
+	{"MB", "MiB", 2},
+	{"GB", "GiB", 3},
+	{"TB", "TiB", 4},
+	{"PB", "PiB", 5},
+	{"EB", "EiB", 6},
+}
+
+// unitPrefix returns the lower case unit without its trailing B, as matched against input
+func unitPrefix(unit string) string {
+	return strings.ToLower(strings.TrimSuffix(unit, "B"))
+}
+
+// parseInteger parses a signed integer of the given bit size, accepting 0x, 0o and 0b prefixes and underscores
+// between digits.  Unlike strconv, a leading zero alone does not make the value octal.
+func parseInteger(value string, bitSize int) (int64, error) {
+	sign := ""
+	digits := value
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	return strconv.ParseInt(sign+trimLeadingZeros(digits), 0, bitSize)
+}
+
+// parseUnsigned is the unsigned counterpart of parseInteger
+func parseUnsigned(value string, bitSize int) (uint64, error) {
+	return strconv.ParseUint(trimLeadingZeros(value), 0, bitSize)
+}
+
+// trimLeadingZeros removes the leading zeros of a decimal value, so that strconv doesn't read it as octal
+func trimLeadingZeros(digits string) string {
+	if len(digits) < 2 || digits[0] != '0' || digits[1] < '0' || digits[1] > '9' {
+		return digits
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return "0" + digits
+	}
+	return digits
+}
+
+// integerError describes why an integer value couldn't be parsed
+func integerError(err error, display string) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("value is out of range for %s", display)
+	}
+	return fmt.Errorf("expected an integer value such as 42, 0x2a or 1_000")
+}
+
+func parseInt(value string) (any, error) {
+	val, err := parseInteger(value, strconv.IntSize)
+	if err != nil {
+		return nil, integerError(err, "int")
+	}
+	return int(val), nil
+}
+
+func parseInt64(value string) (any, error) {
+	val, err := parseInteger(value, 64)
+	if err != nil {
+		return nil, integerError(err, "int64")
+	}
+	return val, nil
+}
+
+func parseUint(value string) (any, error) {
+	val, err := parseUnsigned(value, strconv.IntSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("value is out of range for uint")
+		}
+		return nil, fmt.Errorf("expected a non-negative integer value such as 42, 0x2a or 1_000")
+	}
+	return uint(val), nil
+}
+
+// parseBytes parses a size such as 512, 512k, 1.5G or 10MiB into a number of bytes
+func parseBytes(value string) (any, error) {
+	errExpected := fmt.Errorf("expected a size such as 512, 512k, 1.5G or 10MiB")
+
+	number := strings.TrimRightFunc(value, unicode.IsLetter)
+	unit := strings.ToLower(strings.TrimSpace(value[len(number):]))
+	number = strings.TrimSpace(number)
+	if number == "" || strings.HasPrefix(number, "-") {
+		return nil, errExpected
+	}
+
+	unit = strings.TrimSuffix(unit, "b")
+	multiplier := 1.0
+	if unit != "" {
+		found := false
+		for _, u := range byteUnits {
+			if unit == unitPrefix(u.si) {
+				multiplier, found = math.Pow(1000, float64(u.exp)), true
+			} else if unit == unitPrefix(u.iec) {
+				multiplier, found = math.Pow(1024, float64(u.exp)), true
+			}
+		}
+		if !found {
+			return nil, errExpected
+		}
+	}
+
+	// Whole numbers are multiplied exactly, so that large sizes don't lose precision
+	if whole, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if multiplier > 1 && whole > math.MaxInt64/int64(multiplier) {
+			return nil, fmt.Errorf("size is too large")
+		}
+		return whole * int64(multiplier), nil
+	}
+
+	val, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return nil, errExpected
+	}
+	size := math.Round(val * multiplier)
+	if size >= math.MaxInt64 {
+		return nil, fmt.Errorf("size is too large")
+	}
+	return int64(size), nil
+}
+
+// formatBytes formats a number of bytes for display, using the largest unit which represents it exactly and
+// otherwise rounding to one decimal place of an IEC unit
+func formatBytes(size int64) string {
+	if size == 0 {
+		return "0B"
+	}
+	for idx := len(byteUnits) - 1; idx >= 0; idx-- {
+		u := byteUnits[idx]
+		if iec := int64(math.Pow(1024, float64(u.exp))); size%iec == 0 {
+			return fmt.Sprintf("%d%s", size/iec, u.iec)
+		}
+		if si := int64(math.Pow(1000, float64(u.exp))); size%si == 0 {
+			return fmt.Sprintf("%d%s", size/si, u.si)
+		}
+	}
+	for idx := len(byteUnits) - 1; idx >= 0; idx-- {
+		u := byteUnits[idx]
+		if iec := math.Pow(1024, float64(u.exp)); math.Abs(float64(size)) >= iec {
+			return fmt.Sprintf("%.1f%s", float64(size)/iec, u.iec)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,140 @@
+package artillery
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseIntBases(t *testing.T) {
+	cases := map[string]int{
+		"42":      42,
+		"-42":     -42,
+		"+7":      7,
+		"0x2a":    42,
+		"0X2A":    42,
+		"0o17":    15,
+		"0b101":   5,
+		"1_000":   1000,
+		"010":     10,
+		"-007":    -7,
+		"0":       0,
+		"00":      0,
+		"0xff_ff": 65535,
+	}
+	for input, expected := range cases {
+		val, err := parseInt(input)
+		if err != nil {
+			t.Errorf("Input %s unexpected error %v", input, err)
+			continue
+		}
+		if val != expected {
+			t.Errorf("Input %s expected %d, got %v", input, expected, val)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1_", "0x", "1.5", "0b2"} {
+		if _, err := parseInt(input); err == nil {
+			t.Errorf("Input %q expected an error", input)
+		}
+	}
+
+	if _, err := parseInt64("9223372036854775808"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected an out of range error, got %v", err)
+	}
+	if strconv.IntSize == 64 {
+		if val, err := parseInt64("0x7fffffffffffffff"); err != nil || val != int64(9223372036854775807) {
+			t.Errorf("Unexpected int64 %v %v", val, err)
+		}
+	}
+	if val, err := parseUint("0xff"); err != nil || val != uint(255) {
+		t.Errorf("Unexpected uint %v %v", val, err)
+	}
+	if _, err := parseUint("-1"); err == nil {
+		t.Error("Expected a negative uint to be rejected")
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	cases := map[string]int64{
+		"512":    512,
+		"512B":   512,
+		"512k":   512000,
+		"512kB":  512000,
+		"1KiB":   1024,
+		"10MiB":  10 * 1024 * 1024,
+		"10mib":  10 * 1024 * 1024,
+		"1.5G":   1500000000,
+		"1.5GiB": 1610612736,
+		"2T":     2000000000000,
+		"1EiB":   1 << 60,
+		"0":      0,
+	}
+	for input, expected := range cases {
+		val, err := parseBytes(input)
+		if err != nil {
+			t.Errorf("Input %s unexpected error %v", input, err)
+			continue
+		}
+		if val != expected {
+			t.Errorf("Input %s expected %d, got %v", input, expected, val)
+		}
+	}
+
+	for _, input := range []string{"", "k", "-1k", "10X", "1.5.5M", "10EiB", "ten"} {
+		if _, err := parseBytes(input); err == nil {
+			t.Errorf("Input %q expected an error", input)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		0:                "0B",
+		512:              "512B",
+		1024:             "1KiB",
+		512000:           "500KiB",
+		2000:             "2kB",
+		10 * 1024 * 1024: "10MiB",
+		1500000000:       "1500MB",
+		1 << 60:          "1EiB",
+		1500:             "1.5KiB",
+		1537:             "1.5KiB",
+	}
+	for input, expected := range cases {
+		if display := formatBytes(input); display != expected {
+			t.Errorf("Size %d expected %s, got %s", input, expected, display)
+		}
+	}
+}
+
+func TestCommandBytesHelp(t *testing.T) {
+	cmd := &Command{
+		Name:        "upload",
+		Description: "upload a file",
+		Options: []*Option{
+			{
+				Name:        "chunk",
+				Description: "chunk size",
+				Type:        Bytes,
+				Default:     int64(8 * 1024 * 1024),
+				Min:         1024,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	cmd.WriteHelp(buf)
+	if !strings.Contains(buf.String(), "--chunk=8MiB") || !strings.Contains(buf.String(), "chunk size (min 1KiB)") {
+		t.Errorf("Unexpected help\n%s", buf.String())
+	}
+}
@@ -0,0 +1,111 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// chainLink is a single pipeline within a chain of commands, along with the operator which joins it to
+// the previous pipeline
+type chainLink struct {
+	operator string    // One of ;, && or ||, empty for the first pipeline in the chain
+	stages   [][]*word // Commands making up the pipeline, each feeding its output to the next
+}
+
+// shouldRun determines whether the link executes, given the result of the previously executed command
+func (link *chainLink) shouldRun(lastErr error) bool {
+	switch link.operator {
+	case "&&":
+		return lastErr == nil
+	case "||":
+		return lastErr != nil
+	default:
+		return true
+	}
+}
+
+// splitChain splits lexed words into a chain of pipelines separated by ;, && and ||, and splits each
+// pipeline into its stages.  A trailing ; is permitted, however every other operator must be surrounded
+// by commands.
+func splitChain(words []*word) ([]*chainLink, error) {
+	chain := []*chainLink{}
+	cur := &chainLink{stages: [][]*word{}}
+	stage := []*word{}
+	for _, w := range words {
+		if w.operator == "" || isRedirect(w.operator) {
+			stage = append(stage, w)
+			continue
+		}
+
+		if len(stage) == 0 {
+			return nil, fmt.Errorf("Syntax error near unexpected token \"%s\"", w.operator)
+		}
+		cur.stages = append(cur.stages, stage)
+		stage = []*word{}
+		if w.operator == "|" {
+			continue
+		}
+
+		chain = append(chain, cur)
+		cur = &chainLink{
+			operator: w.operator,
+			stages:   [][]*word{},
+		}
+	}
+
+	if len(stage) > 0 {
+		cur.stages = append(cur.stages, stage)
+		chain = append(chain, cur)
+	} else if len(cur.stages) > 0 {
+		return nil, fmt.Errorf("Syntax error, expected a command following \"|\"")
+	} else if cur.operator != "" && cur.operator != ";" {
+		return nil, fmt.Errorf("Syntax error, expected a command following \"%s\"", cur.operator)
+	}
+
+	// Catch redirections missing their file name before anything in the chain runs
+	for _, link := range chain {
+		for _, stage := range link.stages {
+			if _, _, err := extractRedirects(stage); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return chain, nil
+}
+
+// currentCommand returns the words belonging to the final command in the lexed input, which is the one
+// being completed
+func currentCommand(words []*word) []*word {
+	for idx := len(words) - 1; idx >= 0; idx-- {
+		if words[idx].operator != "" && !isRedirect(words[idx].operator) {
+			return words[idx+1:]
+		}
+	}
+	return words
+}
+
+// stripExternal removes the ! prefix which marks a command for execution by the operating system,
+// returning true if it was present
+func stripExternal(words []*word) ([]*word, bool) {
+	if len(words) == 0 || len(words[0].parts) == 0 {
+		return words, false
+	}
+
+	first := words[0].parts[0]
+	if first.quoting != unquoted || !strings.HasPrefix(first.text, "!") {
+		return words, false
+	}
+
+	if first.text == "!" && len(words[0].parts) == 1 {
+		return words[1:], true
+	}
+
+	stripped := &word{
+		parts: append([]wordPart{}, words[0].parts...),
+		start: words[0].start + 1,
+		end:   words[0].end,
+	}
+	stripped.parts[0].text = first.text[1:]
+	return append([]*word{stripped}, words[1:]...), true
+}
@@ -0,0 +1,160 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitChain(t *testing.T) {
+	cases := []struct {
+		input     string
+		operators []string
+		isError   bool
+	}{
+		{"a", []string{""}, false},
+		{"a; b && c || d", []string{"", ";", "&&", "||"}, false},
+		{"a;", []string{""}, false},
+		{"", []string{}, false},
+		{"; a", nil, true},
+		{"a && && b", nil, true},
+		{"a ||", nil, true},
+	}
+
+	for _, c := range cases {
+		chain, err := splitChain(lex(c.input).words)
+		if c.isError {
+			if err == nil {
+				t.Errorf("Input %q expected a syntax error", c.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Input %q unexpected error %v", c.input, err)
+			continue
+		}
+		if len(chain) != len(c.operators) {
+			t.Errorf("Input %q expected %d links, got %d", c.input, len(c.operators), len(chain))
+			continue
+		}
+		for idx, link := range chain {
+			if link.operator != c.operators[idx] {
+				t.Errorf("Input %q link %d expected operator %q, got %q", c.input, idx, c.operators[idx], link.operator)
+			}
+		}
+	}
+}
+
+func TestProcessorChain(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		isError  bool
+	}{
+		{"ok a; ok b", "a,b", false},
+		{"fail a; ok b", "a,b", false},
+		{"ok a; fail b", "a,b", true},
+		{"ok a && ok b", "a,b", false},
+		{"fail a && ok b", "a", true},
+		{"ok a || ok b", "a", false},
+		{"fail a || ok b", "a,b", false},
+		{"fail a && ok b || ok c", "a,c", false},
+		{"ok a || ok b && ok c", "a,c", false},
+		{"ok 'a;b' && ok \"c || d\"", "a;b,c || d", false},
+		{"fail a; ok $?", "a,1", false},
+		{"ok a # && fail b", "a", false},
+		{"ok a; # fail b", "a", false},
+		{"ok a;#fail b\nok c", "a,c", false},
+	}
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommands(
+		&Command{
+			Name:        "ok",
+			Description: "always succeeds",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return nil
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return fmt.Errorf("failed")
+			},
+		},
+	)
+
+	for _, c := range cases {
+		calls = calls[:0]
+		err := processor.onExecute(nil, c.input, true)
+		if (err != nil) != c.isError {
+			t.Errorf("Input %q expected error %v, got %v", c.input, c.isError, err)
+		}
+		if actual := strings.Join(calls, ","); actual != c.expected {
+			t.Errorf("Input %q expected calls %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestProcessorChainCompletion(t *testing.T) {
+	processor := NewProcessor()
+	processor.AddCommands(
+		&Command{
+			Name:        "ok",
+			Description: "always succeeds",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return nil
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return nil
+			},
+		},
+	)
+
+	sug := processor.OnComplete("ok a; fa", "", "ok a; fa")
+	if len(sug) != 1 || sug[0].Name != "fail" {
+		t.Errorf("Expected completion to restart after the operator, got %v", sug)
+	}
+
+	sug = processor.OnComplete("ok a && help o", "", "ok a && help o")
+	if len(sug) != 1 || sug[0].Name != "ok" {
+		t.Errorf("Expected help completion after the operator, got %v", sug)
+	}
+
+	sug = processor.OnComplete("ok a # fa", "", "ok a # fa")
+	if len(sug) != 0 {
+		t.Errorf("Expected no completion inside of a comment, got %v", sug)
+	}
+}
@@ -2,8 +2,10 @@ package artillery
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -17,10 +19,32 @@ type ArgType string
 var validOptionName = regexp.MustCompile("^[A-Za-z0-9_]+")
 
 const (
-	String ArgType = "string"
-	Int    ArgType = "int"
-	Bool   ArgType = "bool"
-	Float  ArgType = "float"
+	String   ArgType = "string"
+	Int      ArgType = "int"   // Accepts 0x, 0o and 0b prefixes and underscores, ie. 0xff or 1_000
+	Int64    ArgType = "int64" // int64, parsed as Int
+	Uint     ArgType = "uint"  // uint, parsed as Int
+	Bytes    ArgType = "bytes" // int64 number of bytes, ie. 512k, 1.5G or 10MiB (k, M and G are SI, Ki, Mi and Gi are IEC)
+	Bool     ArgType = "bool"
+	Float    ArgType = "float"
+	Duration ArgType = "duration" // time.Duration, ie. 1h30m
+	Time     ArgType = "time"     // time.Time, either absolute, a named day such as yesterday, or relative such as -2h
+	Path     ArgType = "path"     // Filesystem path with a leading ~ expanded, see PathCheck
+	File     ArgType = "file"     // Path which must not be a directory when it exists
+	Dir      ArgType = "dir"      // Path which must be a directory when it exists
+	IP       ArgType = "ip"       // net.IP, either IPv4 or IPv6
+	CIDR     ArgType = "cidr"     // *net.IPNet, ie. 10.0.0.0/8
+	HostPort ArgType = "hostport" // HostAndPort, ie. localhost:8080
+	URL      ArgType = "url"      // *url.URL, which must be absolute, see Schemes
+	JSON     ArgType = "json"     // Inline JSON document or @file.json decoded into any, see Reflect for decoding into structs
+)
+
+// ParseMode determines how options and positional arguments may be ordered on the command line
+type ParseMode int
+
+const (
+	ParseDefault     ParseMode = iota // Inherit the parse mode from the parent command, or the processor
+	ParseStrict                       // Options must precede positional arguments
+	ParseInterleaved                  // Options and positional arguments may be mixed freely
 )
 
 type Namespace map[string]any
@@ -30,10 +54,13 @@ type Command struct {
 	Group       string // If specified, group will be presented in the help and similar items will be displayed together
 	Description string
 	SubCommands []*Command
+	ParseMode   ParseMode // Ordering rules for options and arguments, applies to subcommands unless they declare their own
 
 	// Commands which have subcommands cannot have any of the following
 	Options            []*Option
+	OptionGroups       []*OptionGroup // Relationships between options, such as options which are mutually exclusive
 	Arguments          []*Argument
+	Validate           func(Namespace) error // Checks the parsed namespace before OnExecute, errors are returned as a *ValidationError
 	OnExecute          func(Namespace, *Processor) error
 	OnCompleteOverride func(cmd *Command, tokens []any, processor *Processor) []*ns.AutoComplete
 
@@ -81,6 +108,9 @@ func (cmd *Command) Prepare() error {
 		if cmd.Options != nil && len(cmd.Options) > 0 {
 			return fmt.Errorf("Commands with subcommands cannot have their own options")
 		}
+		if len(cmd.OptionGroups) > 0 {
+			return fmt.Errorf("Commands with subcommands cannot have their own option groups")
+		}
 		if cmd.Arguments != nil && len(cmd.Arguments) > 0 {
 			return fmt.Errorf("Commands with subcommands cannot declare their own arguments")
 		}
@@ -119,11 +149,19 @@ func (cmd *Command) Prepare() error {
 					return fmt.Errorf("Argument name already exists for option \"%s\"", opt.Name)
 				}
 				nameToArgOrOption[opt.Name] = opt
+				if opt.ShortName == 0 {
+					continue
+				}
 				if _, exists := shortNameToName[string(opt.ShortName)]; exists {
 					return fmt.Errorf("Short name already exists for option \"%s\"", opt.Name)
 				}
 				shortNameToName[string(opt.ShortName)] = opt.Name
 			}
+			for _, opt := range cmd.Options {
+				if _, exists := nameToArgOrOption["no-"+opt.Name]; exists && opt.negatable() {
+					return fmt.Errorf("Option name \"no-%s\" conflicts with the negated form of option \"%s\"", opt.Name, opt.Name)
+				}
+			}
 		}
 
 		if len(cmd.Arguments) > 0 {
@@ -141,6 +179,13 @@ func (cmd *Command) Prepare() error {
 				nameToArgOrOption[arg.Name] = arg
 			}
 		}
+
+		for idx, group := range cmd.OptionGroups {
+			err := group.validate(cmd)
+			if err != nil {
+				return fmt.Errorf("Error in command %s option group %d\n%w", cmd.Name, idx, err)
+			}
+		}
 	}
 
 	return nil
@@ -160,11 +205,16 @@ func (cmd *Command) Fullname() string {
 
 // DisplayHelp displays contextual help for the command
 func (cmd *Command) DisplayHelp() {
-	tg.Print(tg.Blue, cmd.Description, tg.Reset, "\n\n")
-	fmt.Println("usage:")
-	fmt.Print(cmd.Name)
+	cmd.WriteHelp(os.Stdout)
+}
+
+// WriteHelp writes contextual help for the command to the supplied writer
+func (cmd *Command) WriteHelp(w io.Writer) {
+	tg.Fprint(w, tg.Blue, cmd.Description, tg.Reset, "\n\n")
+	fmt.Fprintln(w, "usage:")
+	fmt.Fprint(w, cmd.Name)
 	if cmd.SubCommands != nil && len(cmd.SubCommands) > 0 {
-		fmt.Printf(" <subcommand>\n\n")
+		fmt.Fprintf(w, " <subcommand>\n\n")
 		subCommands := make([]*Command, len(cmd.SubCommands))
 		for idx, sub := range cmd.SubCommands {
 			subCommands[idx] = sub
@@ -172,60 +222,71 @@ func (cmd *Command) DisplayHelp() {
 		sort.Slice(subCommands, func(i, j int) bool {
 			return subCommands[i].Name < subCommands[j].Name
 		})
-		fmt.Println("subcommands:")
+		fmt.Fprintln(w, "subcommands:")
 		table := tg.NewTable("subcommand", "description")
 		table.HideHeading = true
 		for _, subCommand := range subCommands {
 			table.Append(subCommand.Name, subCommand.Description)
 		}
-		table.Render()
+		table.RenderTo(w)
 	} else {
 		options := []*Option{}
 		args := []*Argument{}
 
 		if cmd.Options != nil && len(cmd.Options) > 0 {
-			fmt.Print(" [<options...>]")
+			fmt.Fprint(w, " [<options...>]")
 			for _, opt := range cmd.Options {
 				options = append(options, opt)
 			}
 		}
 		if cmd.Arguments != nil && len(cmd.Arguments) > 0 {
 			for _, arg := range cmd.Arguments {
-				fmt.Printf(" %s", arg.Usage())
+				fmt.Fprintf(w, " %s", arg.Usage())
 				args = append(args, arg)
 			}
 		}
-		fmt.Printf("\n\n")
+		fmt.Fprintf(w, "\n\n")
 
 		if len(args) > 0 {
-			fmt.Println("arguments:")
+			fmt.Fprintln(w, "arguments:")
 			sort.Slice(args, func(i, j int) bool {
 				return args[i].Name < args[j].Name
 			})
 			table := tg.NewTable("", "name", "description")
 			table.HideHeading = true
 			for _, arg := range args {
-				table.Append("", arg.Name, arg.Description)
+				table.Append("", arg.Name, arg.constraints().describeWith(arg.Description))
 			}
-			table.Render()
-			fmt.Println()
+			table.RenderTo(w)
+			fmt.Fprintln(w)
 		}
 
 		if len(options) > 0 {
-			fmt.Println("options:")
+			fmt.Fprintln(w, "options:")
 			sort.Slice(options, func(i, j int) bool {
 				return options[i].Name < options[j].Name
 			})
 			table := tg.NewTable("", "name", "description")
 			table.HideHeading = true
 			for _, opt := range cmd.Options {
-				table.Append("", opt.InvocationDisplay(), opt.Description)
+				table.Append("", opt.InvocationDisplay(), opt.helpDescription())
+			}
+			table.RenderTo(w)
+			fmt.Fprintln(w)
+		}
+
+		if len(cmd.OptionGroups) > 0 {
+			fmt.Fprintln(w, "option rules:")
+			table := tg.NewTable("", "rule")
+			table.HideHeading = true
+			for _, group := range cmd.OptionGroups {
+				table.Append("", group.describe())
 			}
-			table.Render()
-			fmt.Println()
+			table.RenderTo(w)
+			fmt.Fprintln(w)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // Process processes the supplied cliArgs as though this were a standalone commmand.  This is useful for processing arguments directly from
@@ -266,11 +327,23 @@ func (cmd *Command) Execute(tokens []any, processor *Processor, fromShell bool)
 	}
 
 	// This branch of code is on a terminal command (ie. no further subcommands), so evaluate args
-	opts, args, err := group(tokens)
+	opts, args, err := group(tokens, cmd.parseMode(processor) == ParseInterleaved)
 	if err != nil {
 		return err
 	}
 
+	// Indexes of the words supplying the positional arguments, see argInput
+	argIndexes := []int{}
+	for _, token := range tokens {
+		switch t := token.(type) {
+		case string:
+			argIndexes = append(argIndexes, 0)
+		case argInput:
+			argIndexes = append(argIndexes, t.index)
+		}
+	}
+
+	supplied := map[string]bool{}
 	for _, opt := range opts {
 		var optName string
 		var ok bool
@@ -284,6 +357,9 @@ func (cmd *Command) Execute(tokens []any, processor *Processor, fromShell bool)
 		}
 
 		optDef, ok := cmd.nameToArgOrOption[optName]
+		if !ok {
+			optDef, ok = cmd.negatedOption(optName)
+		}
 		if !ok {
 			return fmt.Errorf("Option --%s is not recognized.  %s", optName, cmd.helpInvocationStr(fromShell))
 		}
@@ -292,13 +368,19 @@ func (cmd *Command) Execute(tokens []any, processor *Processor, fromShell bool)
 		case *Option:
 			err = t.Apply(opt, namespace)
 			if err != nil {
-				return err
+				return atWord(err, opt.index)
 			}
+			supplied[t.Name] = true
 		default:
 			return fmt.Errorf("Option --%s is not recognized.  %s", optName, cmd.helpInvocationStr(fromShell))
 		}
 	}
 
+	sources, err := cmd.applyFallbacks(namespace, supplied, processor)
+	if err != nil {
+		return err
+	}
+
 	for _, arg := range cmd.Arguments {
 		arg.ApplyArrayDefaults(namespace)
 	}
@@ -317,7 +399,10 @@ func (cmd *Command) Execute(tokens []any, processor *Processor, fromShell bool)
 			}
 
 			argDef := cmd.Arguments[ix]
-			argDef.Apply(arg, namespace)
+			err = argDef.Apply(arg, namespace)
+			if err != nil {
+				return atWord(err, argIndexes[idx])
+			}
 		} else {
 			return fmt.Errorf("Unexpected argument \"%s\".  %s", arg, cmd.helpInvocationStr(fromShell))
 		}
@@ -337,15 +422,105 @@ func (cmd *Command) Execute(tokens []any, processor *Processor, fromShell bool)
 			if opt.IsRequired {
 				v, ok := namespace[opt.Name]
 				if !ok || v == nil {
+					if opt.EnvVar != "" {
+						return fmt.Errorf("Opt %s must be provided, or set by environment variable %s", opt.InvocationDisplay(), opt.EnvVar)
+					}
 					return fmt.Errorf("Opt %s must be provided", opt.InvocationDisplay())
 				}
 			}
 		}
 	}
 
+	// Options with an implicit Value only count as provided when they're set to it, not when set false
+	provided := map[string]bool{}
+	onCommandLine := map[string]bool{}
+	for _, opt := range cmd.Options {
+		source := sources[opt.Name]
+		provided[opt.Name] = source != sourceDefault && source != sourceUnset &&
+			(opt.Value == nil || reflect.DeepEqual(namespace[opt.Name], opt.Value))
+		onCommandLine[opt.Name] = provided[opt.Name] && source == sourceFlag
+	}
+	for _, group := range cmd.OptionGroups {
+		err = group.check(namespace, provided, onCommandLine)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, arg := range cmd.Arguments {
+		if arg.IsArray {
+			err = arg.constraints().checkCount(arrayLength(namespace[arg.Name]))
+			if err != nil {
+				return fmt.Errorf("Argument %s - %s", arg.Name, err)
+			}
+		}
+	}
+	for _, opt := range cmd.Options {
+		if opt.IsArray {
+			err = opt.constraints().checkCount(arrayLength(namespace[opt.Name]))
+			if err != nil {
+				return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			}
+		}
+		if opt.IsMap {
+			err = opt.constraints().checkCount(mapLength(namespace[opt.Name]))
+			if err != nil {
+				return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			}
+		}
+	}
+
+	if cmd.Validate != nil {
+		err = cmd.Validate(namespace)
+		if err != nil {
+			return asValidationError(err)
+		}
+	}
+
 	return cmd.OnExecute(namespace, processor)
 }
 
+// Sources of option values, besides environment variables and config files
+const (
+	sourceFlag    = "flag"
+	sourceDefault = "default"
+	sourceUnset   = "unset"
+)
+
+// applyFallbacks applies environment variables, and then config file values, to the options which weren't
+// supplied on the command line, and returns where the value of each option came from
+func (cmd *Command) applyFallbacks(namespace Namespace, supplied map[string]bool, processor *Processor) (map[string]string, error) {
+	sources := map[string]string{}
+	for _, opt := range cmd.Options {
+		if supplied[opt.Name] {
+			sources[opt.Name] = sourceFlag
+			continue
+		}
+
+		ok, err := opt.ApplyEnv(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sources[opt.Name] = fmt.Sprintf("env %s", opt.EnvVar)
+			continue
+		}
+
+		source, ok, err := processor.applyConfig(cmd, opt, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sources[opt.Name] = source
+		} else if opt.Default != nil {
+			sources[opt.Name] = sourceDefault
+		} else {
+			sources[opt.Name] = sourceUnset
+		}
+	}
+	return sources, nil
+}
+
 func (cmd *Command) OnComplete(tokens []any, processor *Processor) []*ns.AutoComplete {
 	if cmd.OnCompleteOverride != nil {
 		return cmd.OnCompleteOverride(cmd, tokens, processor)
@@ -358,40 +533,12 @@ func (cmd *Command) onComplete(tokens []any, processor *Processor) []*ns.AutoCom
 	sug := []*ns.AutoComplete{}
 
 	// We only operate on arguments
-	if len(cmd.Arguments) == 0 {
+	cmdArg := cmd.completionArgument(tokens)
+	if cmdArg == nil {
 		return sug
 	}
 
-	// Is the current input token an arg?
-	isArg := false
-
 	finalToken := tokens[len(tokens)-1]
-	switch finalToken.(type) {
-	case string:
-		isArg = true
-	}
-
-	if !isArg {
-		return sug
-	}
-
-	// if it's an arg, which arg is it
-	count := 0
-	for _, token := range tokens {
-		switch token.(type) {
-		case string:
-			count++
-		}
-	}
-	if count > len(cmd.Arguments) {
-		if !cmd.Arguments[len(cmd.Arguments)-1].IsArray {
-			// Empty
-			return sug
-		}
-		count = len(cmd.Arguments)
-	}
-
-	cmdArg := cmd.Arguments[count-1]
 	if cmdArg.CompletionFunc != nil {
 		results := cmdArg.CompletionFunc(finalToken.(string), processor)
 		for _, result := range results {
@@ -407,23 +554,92 @@ func (cmd *Command) onComplete(tokens []any, processor *Processor) []*ns.AutoCom
 				})
 			}
 		}
+	} else if rt, ok := lookupType(cmdArg.Type); ok && rt.options.Complete != nil {
+		for _, result := range rt.options.Complete(finalToken.(string), processor) {
+			sug = append(sug, &ns.AutoComplete{
+				Name: result,
+			})
+		}
 	}
 
-	return sug
+	valid := []*ns.AutoComplete{}
+	for _, s := range sug {
+		if cmdArg.validCompletion(s.Name) {
+			valid = append(valid, s)
+		}
+	}
+	return valid
+}
+
+// completionArgument returns the argument which receives the final token, or nil if the final token isn't a
+// positional argument
+func (cmd *Command) completionArgument(tokens []any) *Argument {
+	if len(cmd.Arguments) == 0 || len(tokens) == 0 {
+		return nil
+	}
+	if _, isArg := tokens[len(tokens)-1].(string); !isArg {
+		return nil
+	}
+
+	// if it's an arg, which arg is it
+	count := 0
+	for _, token := range tokens {
+		switch token.(type) {
+		case string:
+			count++
+		}
+	}
+	if count > len(cmd.Arguments) {
+		if !cmd.Arguments[len(cmd.Arguments)-1].IsArray {
+			return nil
+		}
+		count = len(cmd.Arguments)
+	}
+	return cmd.Arguments[count-1]
 }
 
-// CompressTokens compresses any token/value pairs where required into a single *Option.
+// CompressTokens compresses any token/value pairs where required into a single *Option.  Dash prefixed
+// tokens which begin with a digit (ie. -5) are read as values when the option or argument they apply to
+// expects a numeric type, otherwise they are read as short options.
 func (cmd *Command) CompressTokens(tokens []any) ([]any, error) {
 	shortNameToName := cmd.shortNameToName
 	if shortNameToName == nil {
 		shortNameToName = map[string]string{}
 	}
 
+	// Work on a copy, since dash inputs may be expanded in place
+	tokens = append([]any{}, tokens...)
+
 	compressed := []any{}
+	numArgs := 0
 	idx := 0
 	for idx < len(tokens) {
-		token := tokens[idx]
+		token, index := plainToken(tokens[idx])
 		switch t := token.(type) {
+		case dashInput:
+			argDef := cmd.argumentAt(numArgs)
+			if argDef != nil && acceptsDashValue(string(t), argDef.Type) {
+				value := any(string(t))
+				if arg, ok := tokens[idx].(argInput); ok {
+					arg.dash = false
+					value = arg
+				}
+				compressed = append(compressed, value)
+				numArgs++
+				idx++
+				continue
+			}
+
+			// Not a value, so expand into short options and reprocess
+			options := categorizeOption(string(t))
+			for _, option := range options {
+				option.(*OptionInput).index = index
+			}
+			expanded := append(options, tokens[idx+1:]...)
+			tokens = append(tokens[:idx], expanded...)
+			continue
+		case string:
+			numArgs++
 		case *OptionInput:
 			name := t.Name
 			var optAny any
@@ -437,19 +653,34 @@ func (cmd *Command) CompressTokens(tokens []any) ([]any, error) {
 
 			optAny, ok = cmd.nameToArgOrOption[name]
 			if !ok {
-				return nil, fmt.Errorf("Unknown option %s", t.Name)
+				if _, ok := cmd.negatedOption(name); !ok {
+					return nil, fmt.Errorf("Unknown option %s", t.Name)
+				}
+				// Negated options never take a value
+				break
 			}
 
 			switch o := optAny.(type) {
 			case *Option:
-				if o.Value == nil && t.Value == "" {
+				if o.takesValue() && t.Value == "" {
 					if idx < len(tokens)-1 {
-						switch oo := tokens[idx+1].(type) {
+						next, nextIndex := plainToken(tokens[idx+1])
+						switch oo := next.(type) {
 						case string:
 							t.Value = oo
+							t.index = nextIndex
 							compressed = append(compressed, t)
 							idx += 2
 							continue
+						case dashInput:
+							if acceptsDashValue(string(oo), o.Type) {
+								t.Value = string(oo)
+								t.index = nextIndex
+								compressed = append(compressed, t)
+								idx += 2
+								continue
+							}
+							return nil, fmt.Errorf("Option %s requires a companion argument", o.InvocationDisplay())
 						default:
 							return nil, fmt.Errorf("Option %s requires a companion argument", o.InvocationDisplay())
 						}
@@ -459,13 +690,67 @@ func (cmd *Command) CompressTokens(tokens []any) ([]any, error) {
 				}
 			}
 		}
-		compressed = append(compressed, token)
+		compressed = append(compressed, tokens[idx])
 		idx++
 	}
 
 	return compressed, nil
 }
 
+// plainToken returns the token without the index of the word it was read from, along with the index
+func plainToken(token any) (any, int) {
+	if arg, ok := token.(argInput); ok {
+		return arg.plain(), arg.index
+	}
+	return token, 0
+}
+
+// negatedOption returns the bool option which a --no-<name> option name negates
+func (cmd *Command) negatedOption(name string) (*Option, bool) {
+	if !strings.HasPrefix(name, "no-") {
+		return nil, false
+	}
+	opt, ok := cmd.nameToArgOrOption[name[3:]].(*Option)
+	if !ok || !opt.negatable() {
+		return nil, false
+	}
+	return opt, true
+}
+
+// parseMode returns the effective parse mode of the command, taking into account its parent commands
+// and the processor default
+func (cmd *Command) parseMode(processor *Processor) ParseMode {
+	for curCmd := cmd; curCmd != nil; curCmd = curCmd.parentCommand {
+		if curCmd.ParseMode != ParseDefault {
+			return curCmd.ParseMode
+		}
+	}
+
+	if processor != nil && processor.DefaultParseMode != ParseDefault {
+		return processor.DefaultParseMode
+	}
+
+	return ParseStrict
+}
+
+// argumentAt returns the argument definition which receives the positional argument at idx, or nil if
+// there is none
+func (cmd *Command) argumentAt(idx int) *Argument {
+	if len(cmd.Arguments) == 0 {
+		return nil
+	}
+
+	if idx >= len(cmd.Arguments) {
+		last := cmd.Arguments[len(cmd.Arguments)-1]
+		if !last.IsArray {
+			return nil
+		}
+		return last
+	}
+
+	return cmd.Arguments[idx]
+}
+
 func (cmd *Command) helpInvocationStr(fromShell bool) string {
 	if fromShell {
 		return fmt.Sprintf("Type \"help %s\" for usage.", cmd.Fullname())
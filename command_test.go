@@ -2,6 +2,7 @@ package artillery
 
 import (
 	"testing"
+	"time"
 )
 
 func TestCommandMissingArg(t *testing.T) {
@@ -258,3 +259,236 @@ func TestCommandStringArrayDefaultOption(t *testing.T) {
 		return
 	}
 }
+
+func TestCommandNegativeNumbers(t *testing.T) {
+	var result struct {
+		Offset int
+		Scale  float64
+	}
+	cmd := Command{
+		Name:        "move",
+		Description: "move the cursor",
+		Arguments: []*Argument{
+			{
+				Name:        "offset",
+				Description: "number of cells to move",
+				Type:        Int,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "scale",
+				Description: "scaling factor",
+				ShortName:   's',
+				Type:        Float,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("--scale -3 -5")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if result.Offset != -5 {
+		t.Errorf("Expected offset -5, got %d", result.Offset)
+	}
+	if result.Scale != -3 {
+		t.Errorf("Expected scale -3, got %f", result.Scale)
+	}
+}
+
+func TestCommandDashStringArgument(t *testing.T) {
+	var filename string
+	cmd := Command{
+		Name:        "cat",
+		Description: "display a file",
+		Arguments: []*Argument{
+			{
+				Name:        "filename",
+				Description: "file to display",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			filename = ns["filename"].(string)
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("-5")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err == nil {
+		t.Errorf("Should have errored since -5 is not a string argument")
+		return
+	}
+
+	tokens, err = parse("-- -5")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if filename != "-5" {
+		t.Errorf("Expected filename -5, got %s", filename)
+	}
+}
+
+func TestCommandParseMode(t *testing.T) {
+	var force bool
+	deploy := &Command{
+		Name:        "deploy",
+		Description: "deploy a service",
+		SubCommands: []*Command{
+			{
+				Name:        "service",
+				Description: "deploy a single service",
+				Arguments: []*Argument{
+					{
+						Name:        "name",
+						Description: "name of the service",
+					},
+				},
+				Options: []*Option{
+					{
+						Name:        "force",
+						Description: "force the deployment",
+						Type:        Bool,
+						Value:       true,
+					},
+				},
+				OnExecute: func(ns Namespace, processor *Processor) error {
+					force = ns["force"] == true
+					return nil
+				},
+			},
+		},
+	}
+	err := deploy.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("service web --force")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = deploy.Execute(tokens, nil, false)
+	if err == nil {
+		t.Errorf("Strict mode should reject options following positional arguments")
+		return
+	}
+
+	processor := NewProcessor()
+	processor.DefaultParseMode = ParseInterleaved
+	err = deploy.Execute(tokens, processor, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !force {
+		t.Errorf("Expected force to be set")
+	}
+
+	deploy.ParseMode = ParseStrict
+	err = deploy.Execute(tokens, processor, false)
+	if err == nil {
+		t.Errorf("Command parse mode should override the processor default")
+	}
+}
+
+func TestCommandTimeTypes(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	var result struct {
+		Waits []time.Duration
+		Since time.Time
+		Until *time.Time
+	}
+	cmd := Command{
+		Name:        "wait",
+		Description: "wait for a while",
+		Arguments: []*Argument{
+			{
+				Name:        "waits",
+				Description: "durations to wait",
+				Type:        Duration,
+				IsArray:     true,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "since",
+				Description: "start of the period",
+				Type:        Time,
+			},
+			{
+				Name:        "until",
+				Description: "end of the period",
+				Type:        Time,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("--since -2h --until=2024-03-16 1m -30s")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(result.Waits) != 2 || result.Waits[0] != time.Minute || result.Waits[1] != -30*time.Second {
+		t.Errorf("Expected waits [1m -30s], got %v", result.Waits)
+	}
+	if !result.Since.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("Expected since to be two hours ago, got %v", result.Since)
+	}
+	if result.Until == nil || result.Until.Day() != 16 {
+		t.Errorf("Expected until 2024-03-16, got %v", result.Until)
+	}
+	if display := cmd.Options[0].InvocationDisplay(); display != "--since=<time>" {
+		t.Errorf("Expected display --since=<time>, got %s", display)
+	}
+}
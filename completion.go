@@ -0,0 +1,64 @@
+package artillery
+
+import (
+	"strings"
+	"unicode"
+
+	ns "github.com/hashibuto/nilshell"
+)
+
+// escapeCompletions rewrites suggestions for the word being completed so that inserting them into the
+// input reproduces the suggested value, continuing whatever quoting the word is already using.  The shell
+// inserts the remainder of a suggestion following the text after the last space, so suggestions are
+// trimmed to begin at that point.
+func escapeCompletions(lexed *lexResult, beforeAndCursor string, sug []*ns.AutoComplete) []*ns.AutoComplete {
+	typedRaw := ""
+	typedValue := ""
+	if lexed.inWord && len(lexed.words) > 0 {
+		current := lexed.words[len(lexed.words)-1]
+		typedRaw = string([]rune(beforeAndCursor)[current.start:])
+		typedValue = current.String()
+	}
+	trim := strings.LastIndex(typedRaw, " ") + 1
+
+	escaped := make([]*ns.AutoComplete, len(sug))
+	for idx, s := range sug {
+		escaped[idx] = s
+		if !strings.HasPrefix(s.Name, typedValue) {
+			continue
+		}
+
+		rest := s.Name[len(typedValue):]
+		var raw string
+		switch {
+		case lexed.openQuote && lexed.quoteChar == '\'':
+			raw = typedRaw + strings.ReplaceAll(rest, "'", `'\''`)
+		case lexed.openQuote:
+			raw = typedRaw + escapeChars(rest, func(r rune) bool {
+				return strings.ContainsRune("\\\"$`", r)
+			})
+		default:
+			raw = typedRaw + escapeChars(rest, func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(unquotedSpecialChars, r)
+			})
+		}
+
+		escaped[idx] = &ns.AutoComplete{
+			Name: raw[trim:],
+		}
+	}
+
+	return escaped
+}
+
+// escapeChars precedes each character matched by special with a backslash
+func escapeChars(value string, special func(rune) bool) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if special(r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package artillery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashibuto/artillery/pkg/tg"
+)
+
+func makeConfigCommand() *Command {
+	return &Command{
+		Name:        "config",
+		Description: "show the loaded config file, or the effective option values of a command and where they came from",
+		Arguments: []*Argument{
+			{
+				Name:        "command",
+				Description: "command and subcommand if available",
+				IsArray:     true,
+				CompletionFunc: func(prefix string, processor *Processor) []string {
+					commandNames := []string{}
+					for key := range processor.commandLookup {
+						if strings.HasPrefix(key, prefix) {
+							commandNames = append(commandNames, key)
+						}
+					}
+					return commandNames
+				},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			var args struct {
+				Command []string
+			}
+			err := Reflect(ns, &args)
+			if err != nil {
+				return err
+			}
+
+			w := processor.Stdout()
+			if len(args.Command) == 0 {
+				if processor.config == nil {
+					fmt.Fprintln(w, "No config file loaded")
+					return nil
+				}
+
+				tg.Fprint(w, tg.Bold, tg.Blue, processor.config.path, "\n\n", tg.Reset)
+				sections := []string{}
+				for section := range processor.config.sections {
+					sections = append(sections, section)
+				}
+				sort.Strings(sections)
+
+				table := tg.NewTable("section", "option", "value")
+				table.HideHeading = true
+				for _, section := range sections {
+					names := []string{}
+					for name := range processor.config.sections[section] {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						label := ""
+						if section != "" {
+							label = fmt.Sprintf("[%s]", section)
+						}
+						table.Append(label, name, strings.Join(processor.config.sections[section][name], ", "))
+					}
+				}
+				table.RenderTo(w)
+				return nil
+			}
+
+			cmd, err := processor.lookupCommandPath(args.Command)
+			if err != nil {
+				return err
+			}
+			if len(cmd.SubCommands) > 0 {
+				return fmt.Errorf("%s has subcommands, specify one of them", cmd.Fullname())
+			}
+
+			namespace := Namespace{}
+			for _, opt := range cmd.Options {
+				opt.ApplyDefault(namespace)
+			}
+			sources, err := cmd.applyFallbacks(namespace, nil, processor)
+			if err != nil {
+				return err
+			}
+			for _, opt := range cmd.Options {
+				opt.ApplyArrayDefaults(namespace)
+			}
+
+			table := tg.NewTable("option", "value", "source")
+			table.HideHeading = true
+			for _, opt := range cmd.Options {
+				value := ""
+				if namespace[opt.Name] != nil {
+					value = displayValue(namespace[opt.Name], opt.argType())
+				}
+				table.Append("--"+opt.Name, value, sources[opt.Name])
+			}
+			table.RenderTo(w)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,260 @@
+package artillery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configFile holds the option values loaded by LoadConfig, keyed by command fullname and then option name.  The
+// section named "" is global, and applies to every command with an option of that name.
+type configFile struct {
+	path     string
+	sections map[string]map[string][]string
+}
+
+// LoadConfig loads option values from a config file, which are used for options that aren't supplied on the
+// command line or by environment variable, ahead of their Default.  Files ending in .json are read as JSON,
+// and any other file as INI, ie.
+//
+//	region = us-east
+//
+//	[deploy create]
+//	account = staging
+//
+// Sections are named by the command's Fullname, and values outside of any section apply to every command with
+// an option of that name.  Sections for commands with subcommands apply to all of their subcommands, and the
+// most specific section wins.  In JSON, keys naming a command are sections.  Sections and options are checked
+// against the commands already added, so LoadConfig must be called after AddCommand.
+func (p *Processor) LoadConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Unable to read config file %s - %s", path, unwrapPathError(err))
+	}
+
+	var sections map[string]map[string][]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		sections, err = parseJSONConfig(data, func(key string) bool {
+			_, err := p.lookupCommandPath(strings.Fields(key))
+			return err == nil
+		})
+	} else {
+		sections, err = parseINIConfig(bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid config file %s - %s", path, err)
+	}
+
+	for section, values := range sections {
+		if section == "" {
+			for name := range values {
+				if !hasOption(p.commandLookup, name) {
+					return fmt.Errorf("Invalid config file %s - no command has an option \"%s\"", path, name)
+				}
+			}
+			continue
+		}
+		cmd, err := p.lookupCommandPath(strings.Fields(section))
+		if err != nil {
+			return fmt.Errorf("Invalid config file %s - section [%s] %s", path, section, err)
+		}
+		if len(cmd.SubCommands) > 0 {
+			continue
+		}
+		for name := range values {
+			if _, ok := cmd.nameToArgOrOption[name].(*Option); !ok {
+				return fmt.Errorf("Invalid config file %s - section [%s] command has no option \"%s\"", path, section, name)
+			}
+		}
+	}
+
+	p.config = &configFile{
+		path:     path,
+		sections: sections,
+	}
+	return nil
+}
+
+// hasOption returns true if any of the commands, or their subcommands, has an option of the name
+func hasOption(commands map[string]*Command, name string) bool {
+	for _, cmd := range commands {
+		if _, ok := cmd.nameToArgOrOption[name].(*Option); ok {
+			return true
+		}
+		if hasOption(cmd.subCommandLookup, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the values of the option for the command, searching from the command's own section up through
+// its parents to the global section, along with the name of the section they were found in
+func (c *configFile) lookup(cmd *Command, name string) ([]string, string, bool) {
+	for curCmd := cmd; curCmd != nil; curCmd = curCmd.parentCommand {
+		section := curCmd.Fullname()
+		if values, ok := c.sections[section][name]; ok {
+			return values, section, true
+		}
+	}
+	values, ok := c.sections[""][name]
+	return values, "", ok
+}
+
+// applyConfig applies the config file value of the option to the namespace, returning the source of the value
+// or false when the config file has no value for it
+func (p *Processor) applyConfig(cmd *Command, opt *Option, namespace Namespace) (string, bool, error) {
+	if p == nil || p.config == nil {
+		return "", false, nil
+	}
+	values, section, ok := p.config.lookup(cmd, opt.Name)
+	if !ok {
+		return "", false, nil
+	}
+
+	source := fmt.Sprintf("config %s", p.config.path)
+	if section != "" {
+		source = fmt.Sprintf("config %s [%s]", p.config.path, section)
+	}
+	err := opt.applyValues(values, namespace)
+	if err != nil {
+		return "", false, fmt.Errorf("%s (from %s)", err, source)
+	}
+	return source, true, nil
+}
+
+// parseINIConfig reads key = value lines, grouped into [command] sections.  Blank lines and lines beginning
+// with # or ; are ignored, values may be quoted, and repeating a key supplies multiple values to an array.
+func parseINIConfig(r io.Reader) (map[string]map[string][]string, error) {
+	sections := map[string]map[string][]string{"": {}}
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section name", lineNum)
+			}
+			section = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			if section == "" {
+				return nil, fmt.Errorf("line %d: section requires a command name", lineNum)
+			}
+			if _, exists := sections[section]; !exists {
+				sections[section] = map[string][]string{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			if value[0] == '"' {
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid quoted value", lineNum)
+				}
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		}
+		sections[section][key] = append(sections[section][key], value)
+	}
+
+	return sections, scanner.Err()
+}
+
+// parseJSONConfig reads an object whose keys naming a command are sections, and whose other keys are global.
+// Arrays supply multiple values, and objects supply key=value pairs to a map.
+func parseJSONConfig(data []byte, isCommand func(key string) bool) (map[string]map[string][]string, error) {
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&doc)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := jsonPosition(data, syntaxErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %s", line, col, syntaxErr)
+		}
+		return nil, fmt.Errorf("expected an object of options and [command] sections")
+	}
+
+	sections := map[string]map[string][]string{"": {}}
+	for key, value := range doc {
+		if inner, ok := value.(map[string]any); ok && isCommand(key) {
+			section := strings.Join(strings.Fields(key), " ")
+			sections[section] = map[string][]string{}
+			for name, val := range inner {
+				values, err := jsonConfigValues(val)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %s", key, name, err)
+				}
+				sections[section][name] = values
+			}
+			continue
+		}
+
+		values, err := jsonConfigValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", key, err)
+		}
+		sections[""][key] = values
+	}
+
+	return sections, nil
+}
+
+// jsonConfigValues converts a JSON config value into the strings which would be supplied on the command line
+func jsonConfigValues(value any) ([]string, error) {
+	switch t := value.(type) {
+	case []any:
+		values := []string{}
+		for _, item := range t {
+			itemValues, err := jsonConfigValues(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		return values, nil
+	case map[string]any:
+		values := []string{}
+		for key, item := range t {
+			itemValues, err := jsonConfigValues(item)
+			if err != nil || len(itemValues) != 1 {
+				return nil, fmt.Errorf("map values must be strings, numbers or booleans")
+			}
+			values = append(values, key+"="+itemValues[0])
+		}
+		sort.Strings(values)
+		return values, nil
+	case string:
+		return []string{t}, nil
+	case json.Number:
+		return []string{t.String()}, nil
+	case bool:
+		return []string{strconv.FormatBool(t)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value")
+	}
+}
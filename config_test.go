@@ -0,0 +1,261 @@
+package artillery
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProcessorLoadConfig(t *testing.T) {
+	type deployResult struct {
+		Region  string
+		Account string
+		Replica int
+		Tag     []string
+		Label   map[string]string
+	}
+
+	configs := map[string]string{
+		"art.ini": `
+# applies to every command
+region = us-east
+replica = 2
+
+[deploy]
+account = "shared"
+
+[deploy  create]
+replica = 0x3
+tag = a
+tag = 'b c'
+label = env=prod
+`,
+		"art.json": `{
+	"region": "us-east",
+	"replica": 2,
+	"label": {"env": "prod"},
+	"deploy": {"account": "shared"},
+	"deploy create": {"replica": 3, "tag": ["a", "b c"]}
+}`,
+	}
+
+	var result deployResult
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "deploy",
+		Description: "deploy resources",
+		SubCommands: []*Command{
+			{
+				Name:        "create",
+				Description: "create a deployment",
+				Options: []*Option{
+					{Name: "region", Description: "region", EnvVar: "ART_TEST_REGION", IsRequired: true},
+					{Name: "account", Description: "account", Default: "dev"},
+					{Name: "replica", Description: "replicas", Type: Int, Default: 1},
+					{Name: "tag", Description: "tags", IsArray: true},
+					{Name: "label", Description: "labels", IsMap: true},
+				},
+				OnExecute: func(ns Namespace, processor *Processor) error {
+					result = deployResult{}
+					return Reflect(ns, &result)
+				},
+			},
+		},
+	})
+
+	for name, content := range configs {
+		err := processor.LoadConfig(writeConfig(t, name, content))
+		if err != nil {
+			t.Errorf("%s %v", name, err)
+			return
+		}
+
+		t.Setenv("ART_TEST_REGION", "")
+		err = processor.onExecute(nil, "deploy create", true)
+		if err != nil {
+			t.Errorf("%s %v", name, err)
+			return
+		}
+		if result.Region != "us-east" || result.Account != "shared" || result.Replica != 3 ||
+			strings.Join(result.Tag, ",") != "a,b c" || result.Label["env"] != "prod" {
+			t.Errorf("%s unexpected result %+v", name, result)
+		}
+
+		// Flags take precedence over the environment, which takes precedence over the config file
+		t.Setenv("ART_TEST_REGION", "eu-west")
+		err = processor.onExecute(nil, "deploy create --replica 5 --tag=z", true)
+		if err != nil {
+			t.Errorf("%s %v", name, err)
+			return
+		}
+		if result.Region != "eu-west" || result.Replica != 5 || strings.Join(result.Tag, ",") != "z" {
+			t.Errorf("%s unexpected result %+v", name, result)
+		}
+	}
+}
+
+func TestProcessorLoadConfigErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown.ini":  "[deploy remove]\nregion = x\n",
+		"option.ini":   "[deploy create]\nzone = x\n",
+		"syntax.ini":   "region\n",
+		"section.ini":  "[deploy create\n",
+		"syntax.json":  "{\n  \"region\": \"x\",\n}",
+		"invalid.json": "[1, 2]",
+		"global.ini":   "zone = x\n",
+		"global.json":  "{\"deploy remove\": {\"region\": \"x\"}}",
+	}
+	expected := map[string]string{
+		"unknown.ini":  "section [deploy remove] unknown command or subcommand \"remove\"",
+		"option.ini":   "section [deploy create] command has no option \"zone\"",
+		"syntax.ini":   "line 1: expected key = value",
+		"section.ini":  "line 1: unterminated section name",
+		"syntax.json":  "line 3, column 1",
+		"invalid.json": "expected an object",
+		"global.ini":   "no command has an option \"zone\"",
+		"global.json":  "no command has an option \"deploy remove\"",
+	}
+
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "deploy",
+		Description: "deploy resources",
+		SubCommands: []*Command{
+			{
+				Name:        "create",
+				Description: "create a deployment",
+				Options: []*Option{
+					{Name: "region", Description: "region", EnvVar: "ART_TEST_REGION", IsRequired: true},
+					{Name: "account", Description: "account", Default: "dev"},
+					{Name: "replica", Description: "replicas", Type: Int, Default: 1},
+					{Name: "tag", Description: "tags", IsArray: true},
+					{Name: "label", Description: "labels", IsMap: true},
+				},
+				OnExecute: func(ns Namespace, processor *Processor) error {
+					return nil
+				},
+			},
+		},
+	})
+
+	for name, content := range cases {
+		err := processor.LoadConfig(writeConfig(t, name, content))
+		if err == nil || !strings.Contains(err.Error(), expected[name]) {
+			t.Errorf("%s expected error containing %q, got %v", name, expected[name], err)
+		}
+	}
+
+	err := processor.LoadConfig(writeConfig(t, "bad.ini", "replica = many\n"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Setenv("ART_TEST_REGION", "eu-west")
+	err = processor.onExecute(nil, "deploy create", true)
+	if err == nil || !strings.Contains(err.Error(), "(from config") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestProcessorLoadConfigOrder(t *testing.T) {
+	path := writeConfig(t, "art.ini", "[deploy]\nregion = us-east\n")
+
+	processor := NewProcessor()
+	err := processor.LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "section [deploy]") {
+		t.Errorf("Expected the section to be rejected before the command is added, got %v", err)
+	}
+
+	processor.AddCommand(&Command{
+		Name:        "deploy",
+		Description: "deploy resources",
+		Options: []*Option{
+			{Name: "region", Description: "region"},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	})
+	err = processor.LoadConfig(path)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "deploy",
+		Description: "deploy resources",
+		SubCommands: []*Command{
+			{
+				Name:        "create",
+				Description: "create a deployment",
+				Options: []*Option{
+					{Name: "region", Description: "region", EnvVar: "ART_TEST_REGION", IsRequired: true},
+					{Name: "account", Description: "account", Default: "dev"},
+					{Name: "replica", Description: "replicas", Type: Int, Default: 1},
+					{Name: "tag", Description: "tags", IsArray: true},
+					{Name: "label", Description: "labels", IsMap: true},
+				},
+				OnExecute: func(ns Namespace, processor *Processor) error {
+					return nil
+				},
+			},
+		},
+	})
+	path := writeConfig(t, "art.ini", "region = us-east\n[deploy create]\nreplica = 3\n")
+	err := processor.LoadConfig(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Setenv("ART_TEST_REGION", "")
+
+	stdout := &bytes.Buffer{}
+	err = processor.withStreams(nil, stdout, stdout).onExecute(nil, "config deploy create", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	expected := [][]string{
+		{"--account", "dev", "default"},
+		{"--label", "map[]", "unset"},
+		{"--region", "us-east", "config " + path},
+		{"--replica", "3", "config " + path + " [deploy create]"},
+		{"--tag", "[]", "unset"},
+	}
+	if len(lines) != len(expected) {
+		t.Errorf("Unexpected output\n%s", stdout.String())
+		return
+	}
+	for idx, fields := range expected {
+		for _, field := range fields {
+			if !strings.Contains(lines[idx], field) {
+				t.Errorf("Expected line %q to contain %q", lines[idx], field)
+			}
+		}
+	}
+
+	stdout.Reset()
+	err = processor.withStreams(nil, stdout, stdout).onExecute(nil, "config", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(stdout.String(), path) || !strings.Contains(stdout.String(), "[deploy create]") {
+		t.Errorf("Unexpected output\n%s", stdout.String())
+	}
+}
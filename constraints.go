@@ -0,0 +1,316 @@
+package artillery
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// valueConstraints gathers the restrictions which arguments and options share, so that both are checked and
+// described in the same way
+type valueConstraints struct {
+	argType   ArgType
+	isArray   bool
+	isMap     bool
+	min       any
+	max       any
+	minLen    int
+	maxLen    int
+	pattern   string
+	minCount  int
+	maxCount  int
+	pathCheck PathCheck
+	schemes   []string
+}
+
+func (arg *Argument) constraints() *valueConstraints {
+	return &valueConstraints{
+		argType:   arg.Type,
+		isArray:   arg.IsArray,
+		min:       arg.Min,
+		max:       arg.Max,
+		minLen:    arg.MinLen,
+		maxLen:    arg.MaxLen,
+		pattern:   arg.Pattern,
+		minCount:  arg.MinCount,
+		maxCount:  arg.MaxCount,
+		pathCheck: arg.PathCheck,
+		schemes:   arg.Schemes,
+	}
+}
+
+func (opt *Option) constraints() *valueConstraints {
+	return &valueConstraints{
+		argType:   opt.argType(),
+		isArray:   opt.IsArray,
+		isMap:     opt.IsMap,
+		min:       opt.Min,
+		max:       opt.Max,
+		minLen:    opt.MinLen,
+		maxLen:    opt.MaxLen,
+		pattern:   opt.Pattern,
+		minCount:  opt.MinCount,
+		maxCount:  opt.MaxCount,
+		pathCheck: opt.PathCheck,
+		schemes:   opt.Schemes,
+	}
+}
+
+// patterns caches compiled patterns, which are anchored so that they must match the entire value
+var patterns sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// validate ensures that the constraints are meaningful for the type
+func (c *valueConstraints) validate() error {
+	err := validateType(c.argType)
+	if err != nil {
+		return err
+	}
+	rt, _ := lookupType(c.argType)
+
+	for _, bound := range []struct {
+		name  string
+		value any
+	}{{"Min", c.min}, {"Max", c.max}} {
+		if bound.value == nil {
+			continue
+		}
+		if _, err := c.bound(bound.value, rt.elemType); err != nil {
+			return fmt.Errorf("%s %s", bound.name, err)
+		}
+	}
+	if c.min != nil && c.max != nil {
+		if cmp, _ := c.compare(c.max, c.min); cmp < 0 {
+			return fmt.Errorf("Max cannot be less than Min")
+		}
+	}
+
+	if c.minLen != 0 || c.maxLen != 0 || c.pattern != "" {
+		if rt.elemType.Kind() != reflect.String {
+			return fmt.Errorf("MinLen, MaxLen and Pattern may only be used with string types")
+		}
+	}
+	if c.minLen < 0 || c.maxLen < 0 || (c.maxLen != 0 && c.maxLen < c.minLen) {
+		return fmt.Errorf("MinLen and MaxLen must be positive, and MaxLen cannot be less than MinLen")
+	}
+	if c.pattern != "" {
+		if _, err := compilePattern(c.pattern); err != nil {
+			return fmt.Errorf("Invalid Pattern - %s", err)
+		}
+	}
+
+	if (c.minCount != 0 || c.maxCount != 0) && !c.isArray && !c.isMap {
+		return fmt.Errorf("MinCount and MaxCount may only be used with IsArray or IsMap")
+	}
+	if c.minCount < 0 || c.maxCount < 0 || (c.maxCount != 0 && c.maxCount < c.minCount) {
+		return fmt.Errorf("MinCount and MaxCount must be positive, and MaxCount cannot be less than MinCount")
+	}
+
+	err = validatePathCheck(c.pathCheck, c.argType)
+	if err != nil {
+		return err
+	}
+	return validateSchemes(c.schemes, c.argType)
+}
+
+// bound converts a Min or Max bound to the type being constrained.  Bounds must be of the same type, except
+// that any number may bound a plain numeric type (ie. an int Min for a float).
+func (c *valueConstraints) bound(value any, elemType reflect.Type) (reflect.Value, error) {
+	val := reflect.ValueOf(value)
+	if val.Type() == elemType && (isNumericKind(elemType.Kind()) || elemType == reflect.TypeOf(time.Time{})) {
+		return val, nil
+	}
+	if elemType.PkgPath() == "" && isNumericKind(elemType.Kind()) && isNumericKind(val.Kind()) {
+		return val.Convert(elemType), nil
+	}
+	return reflect.Value{}, fmt.Errorf("must be a %s to constrain the %s type", elemType, typeDisplay(c.argType))
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// compare returns -1, 0 or 1 as the value is less than, equal to or greater than the bound, and false if the
+// two can't be compared
+func (c *valueConstraints) compare(value any, bound any) (int, bool) {
+	val := reflect.ValueOf(value)
+	b, err := c.bound(bound, val.Type())
+	if err != nil {
+		return 0, false
+	}
+
+	if t, ok := value.(time.Time); ok {
+		bt := b.Interface().(time.Time)
+		switch {
+		case t.Before(bt):
+			return -1, true
+		case t.After(bt):
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	var less, greater bool
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = val.Int() < b.Int(), val.Int() > b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less, greater = val.Uint() < b.Uint(), val.Uint() > b.Uint()
+	default:
+		less, greater = val.Float() < b.Float(), val.Float() > b.Float()
+	}
+
+	switch {
+	case less:
+		return -1, true
+	case greater:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// check applies the constraints to a single converted value
+func (c *valueConstraints) check(value any) error {
+	if path, ok := value.(string); ok {
+		err := checkPath(path, c.argType, c.pathCheck)
+		if err != nil {
+			return err
+		}
+	}
+	err := checkScheme(value, c.schemes)
+	if err != nil {
+		return err
+	}
+
+	if c.min != nil {
+		if cmp, ok := c.compare(value, c.min); ok && cmp < 0 {
+			return fmt.Errorf("must be at least %s", displayValue(c.min, c.argType))
+		}
+	}
+	if c.max != nil {
+		if cmp, ok := c.compare(value, c.max); ok && cmp > 0 {
+			return fmt.Errorf("must be at most %s", displayValue(c.max, c.argType))
+		}
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.String {
+		str := val.String()
+		length := utf8.RuneCountInString(str)
+		if length < c.minLen {
+			return fmt.Errorf("must be at least %d characters long", c.minLen)
+		}
+		if c.maxLen != 0 && length > c.maxLen {
+			return fmt.Errorf("must be at most %d characters long", c.maxLen)
+		}
+		if c.pattern != "" {
+			re, err := compilePattern(c.pattern)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(str) {
+				return fmt.Errorf("must match the pattern %s", c.pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCount applies the count constraints to the number of values supplied for an array, or keys for a map
+func (c *valueConstraints) checkCount(count int) error {
+	if count < c.minCount {
+		return fmt.Errorf("requires at least %d values", c.minCount)
+	}
+	if c.maxCount != 0 && count > c.maxCount {
+		return fmt.Errorf("accepts at most %d values", c.maxCount)
+	}
+	return nil
+}
+
+// describe lists the constraints for display in help
+func (c *valueConstraints) describe() []string {
+	desc := []string{}
+	if c.min != nil {
+		desc = append(desc, fmt.Sprintf("min %s", displayValue(c.min, c.argType)))
+	}
+	if c.max != nil {
+		desc = append(desc, fmt.Sprintf("max %s", displayValue(c.max, c.argType)))
+	}
+	if c.minLen != 0 {
+		desc = append(desc, fmt.Sprintf("min length %d", c.minLen))
+	}
+	if c.maxLen != 0 {
+		desc = append(desc, fmt.Sprintf("max length %d", c.maxLen))
+	}
+	if c.pattern != "" {
+		desc = append(desc, fmt.Sprintf("pattern %s", c.pattern))
+	}
+	if c.minCount != 0 {
+		desc = append(desc, fmt.Sprintf("min count %d", c.minCount))
+	}
+	if c.maxCount != 0 {
+		desc = append(desc, fmt.Sprintf("max count %d", c.maxCount))
+	}
+	if c.pathCheck&PathExists != 0 {
+		desc = append(desc, "must exist")
+	}
+	if c.pathCheck&PathReadable != 0 {
+		desc = append(desc, "must be readable")
+	}
+	if c.pathCheck&PathAbsent != 0 {
+		desc = append(desc, "must not exist")
+	}
+	if len(c.schemes) > 0 {
+		desc = append(desc, fmt.Sprintf("schemes %s", strings.Join(c.schemes, ", ")))
+	}
+	return desc
+}
+
+// describeWith appends the constraints to a description for display in help
+func (c *valueConstraints) describeWith(description string) string {
+	desc := c.describe()
+	if len(desc) == 0 {
+		return description
+	}
+	return fmt.Sprintf("%s (%s)", description, strings.Join(desc, ", "))
+}
+
+// displayValue formats a value of the type for display in help and errors
+func displayValue(value any, argType ArgType) string {
+	if val := reflect.ValueOf(value); argType == Bytes && isNumericKind(val.Kind()) {
+		return formatBytes(val.Convert(reflect.TypeOf(int64(0))).Int())
+	}
+	switch t := value.(type) {
+	case float64:
+		return fmt.Sprintf("%g", t)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
@@ -0,0 +1,175 @@
+package artillery
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandConstraints(t *testing.T) {
+	cmd := Command{
+		Name:        "create",
+		Description: "create a user",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "user name",
+				MinLen:      3,
+				MaxLen:      8,
+				Pattern:     "[a-z]+",
+			},
+			{
+				Name:        "tags",
+				Description: "tags to apply",
+				IsArray:     true,
+				MinCount:    1,
+				MaxCount:    2,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "age",
+				Description: "age in years",
+				Type:        Int,
+				Min:         18,
+				Max:         130,
+			},
+			{
+				Name:        "score",
+				Description: "initial score",
+				Type:        Float,
+				Min:         0,
+				Max:         1.5,
+			},
+			{
+				Name:        "expiry",
+				Description: "time until expiry",
+				Type:        Duration,
+				Min:         time.Hour,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{"bob admin", ""},
+		{"--age=18 --score=1.5 --expiry=2h bob admin ops", ""},
+		{"bo admin", "Argument name - must be at least 3 characters long"},
+		{"robertson admin", "Argument name - must be at most 8 characters long"},
+		{"Bob admin", "Argument name - must match the pattern [a-z]+"},
+		{"bob", "Argument tags - requires at least 1 values"},
+		{"bob a b c", "Argument tags - accepts at most 2 values"},
+		{"--age=17 bob admin", "must be at least 18"},
+		{"--age=131 bob admin", "must be at most 130"},
+		{"--score=-0.5 bob admin", "must be at least 0"},
+		{"--score=1.6 bob admin", "must be at most 1.5"},
+		{"--expiry=30m bob admin", "must be at least 1h0m0s"},
+	}
+
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("Input %q unexpected error %v", c.input, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("Input %q expected error containing %q, got %v", c.input, c.err, err)
+		}
+	}
+}
+
+func TestCommandConstraintsValidation(t *testing.T) {
+	cases := []*Option{
+		{Name: "count", Description: "count", Type: Int, Min: "1"},
+		{Name: "count", Description: "count", Type: Int, Min: 5, Max: 1},
+		{Name: "expiry", Description: "expiry", Type: Duration, Min: 5},
+		{Name: "name", Description: "name", Type: Int, MinLen: 1},
+		{Name: "name", Description: "name", MinLen: 5, MaxLen: 2},
+		{Name: "name", Description: "name", Pattern: "[a-"},
+		{Name: "tags", Description: "tags", MinCount: 1},
+	}
+
+	for _, opt := range cases {
+		if err := opt.Validate(); err == nil {
+			t.Errorf("Option %+v expected a validation error", opt)
+		}
+	}
+}
+
+func TestCommandConstraintsHelp(t *testing.T) {
+	cmd := Command{
+		Name:        "create",
+		Description: "create a user",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "user name",
+				MinLen:      3,
+				MaxLen:      8,
+				Pattern:     "[a-z]+",
+			},
+			{
+				Name:        "tags",
+				Description: "tags to apply",
+				IsArray:     true,
+				MinCount:    1,
+				MaxCount:    2,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "age",
+				Description: "age in years",
+				Type:        Int,
+				Min:         18,
+				Max:         130,
+			},
+			{
+				Name:        "expiry",
+				Description: "time until expiry",
+				Type:        Duration,
+				Min:         time.Hour,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	cmd.WriteHelp(buf)
+	help := buf.String()
+	for _, expected := range []string{
+		"user name (min length 3, max length 8, pattern [a-z]+)",
+		"tags to apply (min count 1, max count 2)",
+		"age in years (min 18, max 130)",
+		"time until expiry (min 1h0m0s)",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("Expected help to contain %q\n%s", expected, help)
+		}
+	}
+}
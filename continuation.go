@@ -0,0 +1,80 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+
+	ns "github.com/hashibuto/nilshell"
+)
+
+// needsContinuation returns true if the input is incomplete, because it ends inside of a quotation or
+// with a backslash
+func needsContinuation(input string) bool {
+	lexed := lex(input)
+	return lexed.openQuote || lexed.openEscape
+}
+
+// historyTail returns the most recent command of the history, which the shell won't record again if it's
+// repeated by the next line
+func historyTail(history *ns.History) string {
+	commands := history.Export()
+	if len(commands) == 0 {
+		return ""
+	}
+	return commands[len(commands)-1]
+}
+
+// historySize returns the number of commands which the history retains, found by appending to a copy of it
+func historySize(history *ns.History) int {
+	probe := *history
+	for idx := 0; ; idx++ {
+		length := len(probe.Export())
+		probe.Append(fmt.Sprintf("\x00%d", idx))
+		if len(probe.Export()) == length {
+			return length
+		}
+	}
+}
+
+// continueInput accumulates lines of interactive input until they form a complete command, switching to
+// the continuation prompt while more input is required.  It returns the joined command and true once
+// the command is complete.
+func (p *Processor) continueInput(nilShell *ns.NilShell, line string) (string, bool) {
+	if len(p.continuation) == 0 {
+		p.prompt = nilShell.Prompt
+		p.historyAppended = line != p.historyTail
+	}
+	p.continuation = append(p.continuation, line)
+
+	input := strings.Join(p.continuation, "\n")
+	if needsContinuation(input) {
+		if len(p.continuation) == 1 {
+			// The remaining lines are recorded in a scratch history, and replaced by the joined command
+			p.history = nilShell.History
+			commands := p.history.Export()
+			nilShell.History = ns.NewHistory(len(commands)+1, commands...)
+		}
+		nilShell.Prompt = p.ContinuationPrompt
+		return "", false
+	}
+
+	if len(p.continuation) > 1 {
+		nilShell.Prompt = p.prompt
+		nilShell.History = p.history
+		p.replaceHistory(input)
+	}
+	p.continuation = nil
+	return input, true
+}
+
+// replaceHistory swaps the first line of a continued command in the shell history for the joined command,
+// keeping the size of the history
+func (p *Processor) replaceHistory(input string) {
+	commands := p.history.Export()
+	if p.historyAppended {
+		commands = commands[:len(commands)-1]
+	}
+	history := ns.NewHistory(historySize(p.history), commands...)
+	history.Append(input)
+	*p.history = *history
+}
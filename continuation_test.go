@@ -0,0 +1,102 @@
+package artillery
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	ns "github.com/hashibuto/nilshell"
+)
+
+func TestNeedsContinuation(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{"emit a", false},
+		{`emit "a`, true},
+		{"emit 'a", true},
+		{`emit a\`, true},
+		{`emit a\\`, false},
+		{"emit 'a\\", true},
+		{"emit \"a\nb\"", false},
+		{"emit a\\\nb", false},
+	}
+
+	for _, c := range cases {
+		if result := needsContinuation(c.input); result != c.expected {
+			t.Errorf("Input %q expected %v, got %v", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestProcessorContinuation(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommand(&Command{
+		Name:        "emit",
+		Description: "writes each argument on its own line",
+		Arguments: []*Argument{
+			{
+				Name:        "lines",
+				Description: "lines to write",
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			for _, line := range ns["lines"].([]string) {
+				fmt.Fprintln(processor.Stdout(), line)
+			}
+			return nil
+		},
+	})
+	shell := processor.Shell()
+	history := ns.NewHistory(3, "emit older", `emit "first`)
+	shell.History = history
+	processor.historyTail = historyTail(history)
+
+	// The first line repeats the previous entry, so isn't recorded again, and the second line is repeated
+	lines := []string{`emit "first`, "second", "second", `third"`}
+	for idx, line := range lines {
+		shell.History.Append(line)
+		processor.OnExecute(shell, line)
+		if idx < len(lines)-1 && shell.Prompt != processor.ContinuationPrompt {
+			t.Errorf("Expected the continuation prompt after line %d, got %q", idx, shell.Prompt)
+		}
+	}
+
+	if shell.Prompt != "» " {
+		t.Errorf("Expected the prompt to be restored, got %q", shell.Prompt)
+	}
+	if shell.History != history {
+		t.Errorf("Expected the history to be kept")
+	}
+	if stdout.String() != "first\nsecond\nsecond\nthird\n" {
+		t.Errorf("Expected output %q, got %q", "first\nsecond\nsecond\nthird\n", stdout.String())
+	}
+	expected := []string{"emit older", `emit "first`, "emit \"first\nsecond\nsecond\nthird\""}
+	if history := shell.History.Export(); !reflect.DeepEqual(history, expected) {
+		t.Errorf("Expected history\n%q\ngot\n%q", expected, history)
+	}
+
+	// Lines recorded by the shell are replaced, keeping the size of the history
+	lines = []string{"emit 'a", "b'"}
+	for _, line := range lines {
+		shell.History.Append(line)
+		processor.OnExecute(shell, line)
+	}
+	expected = []string{`emit "first`, "emit \"first\nsecond\nsecond\nthird\"", "emit 'a\nb'"}
+	if history := shell.History.Export(); !reflect.DeepEqual(history, expected) {
+		t.Errorf("Expected history\n%q\ngot\n%q", expected, history)
+	}
+
+	// Without a shell, input is executed as is
+	stdout.Reset()
+	processor.OnExecute(nil, "emit 'a")
+	processor.OnExecute(nil, "emit b")
+	if stdout.String() != "b\n" {
+		t.Errorf("Expected output %q, got %q", "b\n", stdout.String())
+	}
+}
@@ -0,0 +1,164 @@
+package artillery
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type envResult struct {
+	Token   string
+	Retries int
+	Header  []string
+	Debug   bool
+	Color   bool
+}
+
+func TestCommandEnvVar(t *testing.T) {
+	cases := []struct {
+		input    string
+		env      map[string]string
+		expected envResult
+	}{
+		{
+			"--token=flag",
+			map[string]string{},
+			envResult{Token: "flag", Retries: 1, Header: []string{}, Color: true},
+		},
+		{
+			"",
+			map[string]string{"ART_TEST_TOKEN": "env", "ART_TEST_RETRIES": "0x10", "ART_TEST_HEADERS": "a,b", "ART_TEST_DEBUG": "true"},
+			envResult{Token: "env", Retries: 16, Header: []string{"a", "b"}, Debug: true, Color: true},
+		},
+		{
+			"--token=flag --retries=3 --header=c",
+			map[string]string{"ART_TEST_TOKEN": "env", "ART_TEST_RETRIES": "5", "ART_TEST_HEADERS": "a,b", "ART_TEST_DEBUG": "false"},
+			envResult{Token: "flag", Retries: 3, Header: []string{"c"}, Color: true},
+		},
+		{
+			"--token=flag",
+			map[string]string{"ART_TEST_COLOR": "false"},
+			envResult{Token: "flag", Retries: 1, Header: []string{}},
+		},
+	}
+
+	var result envResult
+	cmd := Command{
+		Name:        "call",
+		Description: "call the api",
+		Options: []*Option{
+			{
+				Name:        "token",
+				Description: "api token",
+				EnvVar:      "ART_TEST_TOKEN",
+				IsRequired:  true,
+			},
+			{
+				Name:        "retries",
+				Description: "number of retries",
+				Type:        Int,
+				Default:     1,
+				EnvVar:      "ART_TEST_RETRIES",
+			},
+			{
+				Name:        "header",
+				Description: "headers to send",
+				IsArray:     true,
+				EnvVar:      "ART_TEST_HEADERS",
+			},
+			{
+				Name:        "debug",
+				Description: "enable debugging",
+				Value:       true,
+				EnvVar:      "ART_TEST_DEBUG",
+			},
+			{
+				Name:        "color",
+				Description: "colorize output",
+				Value:       true,
+				Default:     true,
+				EnvVar:      "ART_TEST_COLOR",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			result = envResult{}
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, c := range cases {
+		for _, name := range []string{"ART_TEST_TOKEN", "ART_TEST_RETRIES", "ART_TEST_HEADERS", "ART_TEST_DEBUG", "ART_TEST_COLOR"} {
+			t.Setenv(name, c.env[name])
+		}
+
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if err != nil {
+			t.Errorf("Input %q unexpected error %v", c.input, err)
+			continue
+		}
+		if result.Token != c.expected.Token || result.Retries != c.expected.Retries || result.Debug != c.expected.Debug || result.Color != c.expected.Color ||
+			strings.Join(result.Header, ",") != strings.Join(c.expected.Header, ",") {
+			t.Errorf("Input %q expected %+v, got %+v", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestCommandEnvVarErrors(t *testing.T) {
+	cmd := Command{
+		Name:        "call",
+		Description: "call the api",
+		Options: []*Option{
+			{
+				Name:        "token",
+				Description: "api token",
+				EnvVar:      "ART_TEST_TOKEN",
+				IsRequired:  true,
+			},
+			{
+				Name:        "retries",
+				Description: "number of retries",
+				Type:        Int,
+				Default:     1,
+				EnvVar:      "ART_TEST_RETRIES",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Setenv("ART_TEST_TOKEN", "")
+	t.Setenv("ART_TEST_RETRIES", "")
+	err = cmd.Execute([]any{}, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "or set by environment variable ART_TEST_TOKEN") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	t.Setenv("ART_TEST_TOKEN", "env")
+	t.Setenv("ART_TEST_RETRIES", "many")
+	err = cmd.Execute([]any{}, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "(from environment variable ART_TEST_RETRIES)") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	cmd.WriteHelp(buf)
+	if !strings.Contains(buf.String(), "api token [env ART_TEST_TOKEN]") {
+		t.Errorf("Unexpected help\n%s", buf.String())
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
 	"log"
 	"sort"
 	"strconv"
@@ -70,7 +72,7 @@ func makeAnimalCommand() *artillery.Command {
 						Attributes: args.Attribute,
 					}
 					TheZoo.Animals = append(TheZoo.Animals, animal)
-					tg.Println(tg.Green, "Added a ", args.Animal, " to the zoo", tg.Reset)
+					tg.Fprintln(processor.Stdout(), tg.Green, "Added a ", args.Animal, " to the zoo", tg.Reset)
 					return nil
 				},
 			},
@@ -92,7 +94,7 @@ func makeAnimalCommand() *artillery.Command {
 
 					for idx, a := range TheZoo.Animals {
 						if a.Type == args.Animal {
-							tg.Println(tg.Green, "Removed one ", args.Animal, " from the zoo", tg.Reset)
+							tg.Fprintln(processor.Stdout(), tg.Green, "Removed one ", args.Animal, " from the zoo", tg.Reset)
 							remaining := []*Animal{}
 							remaining = append(remaining, TheZoo.Animals[:idx]...)
 							remaining = append(remaining, TheZoo.Animals[idx+1:]...)
@@ -133,13 +135,30 @@ func makeListCommand() *artillery.Command {
 			for _, aType := range animalTypes {
 				table.Append(aType, strconv.Itoa(counts[aType]))
 			}
-			table.Render()
+			table.RenderTo(processor.Stdout())
 
 			return nil
 		},
 	}
 }
 
+func makeCountCommand() *artillery.Command {
+	return &artillery.Command{
+		Name:        "count",
+		Description: "count the lines of input, ie. list | count",
+		OnExecute: func(ns artillery.Namespace, processor *artillery.Processor) error {
+			count := 0
+			scanner := bufio.NewScanner(processor.Stdin())
+			for scanner.Scan() {
+				count++
+			}
+			fmt.Fprintln(processor.Stdout(), count)
+
+			return scanner.Err()
+		},
+	}
+}
+
 func main() {
 	processor := artillery.NewProcessor()
 	processor.DefaultHeading = "uncategorized commands"
@@ -147,6 +166,7 @@ func main() {
 	cmds := []func() *artillery.Command{
 		makeAnimalCommand,
 		makeListCommand,
+		makeCountCommand,
 	}
 
 	for _, c := range cmds {
@@ -159,5 +179,8 @@ func main() {
 	shell := processor.Shell()
 	//shell.Prompt = "\033[33martillery \033[34m\033[1m$ \033[0m"
 	shell.AutoCompleteSuggestStyle = "\033[32m"
-	shell.ReadUntilTerm()
+	err := processor.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
 }
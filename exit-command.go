@@ -5,7 +5,9 @@ func makeExitCommand() *Command {
 		Name:        "exit",
 		Description: "exit the shell",
 		OnExecute: func(ns Namespace, processor *Processor) error {
-			processor.Shell().Shutdown()
+			if processor.exit() {
+				processor.Shell().Shutdown()
+			}
 			return nil
 		},
 	}
@@ -5,9 +5,7 @@ go 1.19
 require (
 	github.com/hashibuto/mirage v0.2.6
 	github.com/hashibuto/nilshell v0.1.16
+	golang.org/x/term v0.3.0
 )
 
-require (
-	golang.org/x/sys v0.3.0 // indirect
-	golang.org/x/term v0.3.0 // indirect
-)
+require golang.org/x/sys v0.3.0 // indirect
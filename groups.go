@@ -0,0 +1,156 @@
+package artillery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GroupKind is the relationship between the options of an OptionGroup
+type GroupKind int
+
+const (
+	ExactlyOneOf GroupKind = iota + 1 // Exactly one of the options must be provided
+	AtMostOneOf                       // No more than one of the options may be provided
+	AllOrNone                         // Either all of the options are provided or none of them are
+	RequiredIf                        // All of the options must be provided when the If option is provided, or has the Equals value
+)
+
+// OptionGroup declares a relationship between options of a command, which is checked once the command line
+// has been parsed.  An option is provided when it's supplied on the command line, by environment variable or
+// by config file, but not when it only has its Default, or when an option with an implicit Value is set false.
+// Only options supplied on the command line conflict in ExactlyOneOf and AtMostOneOf groups, so a value from
+// the environment or config file never prevents choosing another option of the group.
+type OptionGroup struct {
+	Kind    GroupKind
+	Options []string // Names of the options in the group
+	If      string   // Name of the option which makes the group required, RequiredIf only
+	Equals  any      // Value of the If option which makes the group required, any provided value when nil (RequiredIf only)
+}
+
+// validate ensures that the group refers to options of the command and is meaningful for its kind
+func (g *OptionGroup) validate(cmd *Command) error {
+	minOptions := 2
+	switch g.Kind {
+	case ExactlyOneOf, AtMostOneOf, AllOrNone:
+		if g.If != "" || g.Equals != nil {
+			return fmt.Errorf("If and Equals may only be used with RequiredIf groups")
+		}
+	case RequiredIf:
+		minOptions = 1
+		opt, ok := cmd.nameToArgOrOption[g.If].(*Option)
+		if !ok {
+			return fmt.Errorf("RequiredIf group refers to unknown option \"%s\"", g.If)
+		}
+		if g.Equals != nil {
+			if rt, _ := lookupType(opt.argType()); opt.IsArray || opt.IsMap || reflect.TypeOf(g.Equals) != rt.elemType {
+				return fmt.Errorf("Equals must be a %s value of option --%s", opt.ArgTypeDisplay(), opt.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("Option group requires a Kind")
+	}
+
+	if len(g.Options) < minOptions {
+		return fmt.Errorf("Option group requires at least %d options", minOptions)
+	}
+	seen := map[string]bool{}
+	for _, name := range g.Options {
+		if _, ok := cmd.nameToArgOrOption[name].(*Option); !ok {
+			return fmt.Errorf("Option group refers to unknown option \"%s\"", name)
+		}
+		if seen[name] || name == g.If {
+			return fmt.Errorf("Option group refers to option \"%s\" more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// check applies the group to the options provided for a command, of which those in onCommandLine were
+// supplied on the command line
+func (g *OptionGroup) check(namespace Namespace, provided map[string]bool, onCommandLine map[string]bool) error {
+	present := []string{}
+	missing := []string{}
+	conflicting := []string{}
+	for _, name := range g.Options {
+		if provided[name] {
+			present = append(present, name)
+		} else {
+			missing = append(missing, name)
+		}
+		if onCommandLine[name] {
+			conflicting = append(conflicting, name)
+		}
+	}
+
+	switch g.Kind {
+	case ExactlyOneOf:
+		if len(present) == 0 {
+			return fmt.Errorf("Exactly one of %s must be provided", joinOptions(g.Options, "or"))
+		}
+		fallthrough
+	case AtMostOneOf:
+		if len(conflicting) > 1 {
+			return fmt.Errorf("Only one of %s may be provided, got %s", joinOptions(g.Options, "or"), joinOptions(conflicting, "and"))
+		}
+	case AllOrNone:
+		if len(present) > 0 && len(missing) > 0 {
+			return fmt.Errorf("%s must be provided together, missing %s", joinOptions(g.Options, "and"), joinOptions(missing, "and"))
+		}
+	case RequiredIf:
+		if g.applies(namespace, provided) && len(missing) > 0 {
+			return fmt.Errorf("%s %s required when %s, missing %s", joinOptions(g.Options, "and"), pluralVerb(g.Options), g.condition(), joinOptions(missing, "and"))
+		}
+	}
+	return nil
+}
+
+// applies returns true when the condition of a RequiredIf group is met
+func (g *OptionGroup) applies(namespace Namespace, provided map[string]bool) bool {
+	if g.Equals == nil {
+		return provided[g.If]
+	}
+	return reflect.DeepEqual(namespace[g.If], g.Equals)
+}
+
+// condition describes the condition of a RequiredIf group
+func (g *OptionGroup) condition() string {
+	if g.Equals == nil {
+		return fmt.Sprintf("--%s is provided", g.If)
+	}
+	return fmt.Sprintf("--%s is %s", g.If, displayValue(g.Equals, ""))
+}
+
+// describe describes the group for display in help
+func (g *OptionGroup) describe() string {
+	switch g.Kind {
+	case ExactlyOneOf:
+		return fmt.Sprintf("exactly one of %s", joinOptions(g.Options, "or"))
+	case AtMostOneOf:
+		return fmt.Sprintf("at most one of %s", joinOptions(g.Options, "or"))
+	case AllOrNone:
+		return fmt.Sprintf("all or none of %s", joinOptions(g.Options, "and"))
+	default:
+		return fmt.Sprintf("%s %s required when %s", joinOptions(g.Options, "and"), pluralVerb(g.Options), g.condition())
+	}
+}
+
+// joinOptions lists option names for display, ie. --a, --b or --c
+func joinOptions(names []string, conjunction string) string {
+	display := make([]string, len(names))
+	for idx, name := range names {
+		display[idx] = "--" + name
+	}
+	if len(display) == 1 {
+		return display[0]
+	}
+	return fmt.Sprintf("%s %s %s", strings.Join(display[:len(display)-1], ", "), conjunction, display[len(display)-1])
+}
+
+func pluralVerb(names []string) string {
+	if len(names) == 1 {
+		return "is"
+	}
+	return "are"
+}
@@ -0,0 +1,184 @@
+package artillery
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCommandOptionGroups(t *testing.T) {
+	cmd := Command{
+		Name:        "connect",
+		Description: "connect to a server",
+		Options: []*Option{
+			{Name: "host", Description: "host name"},
+			{Name: "socket", Description: "unix socket"},
+			{Name: "json", Description: "json output", Value: true, EnvVar: "ART_TEST_JSON"},
+			{Name: "yaml", Description: "yaml output", Value: true},
+			{Name: "cert", Description: "client certificate"},
+			{Name: "key", Description: "client key"},
+			{Name: "auth", Description: "authentication method", Default: "none"},
+			{Name: "user", Description: "user name"},
+			{Name: "password", Description: "password", EnvVar: "ART_TEST_PASSWORD"},
+			{Name: "proxy", Description: "proxy address"},
+			{Name: "proxy_port", Description: "proxy port", Type: Int},
+		},
+		OptionGroups: []*OptionGroup{
+			{Kind: ExactlyOneOf, Options: []string{"host", "socket"}},
+			{Kind: AtMostOneOf, Options: []string{"json", "yaml"}},
+			{Kind: AllOrNone, Options: []string{"cert", "key"}},
+			{Kind: RequiredIf, Options: []string{"user", "password"}, If: "auth", Equals: "basic"},
+			{Kind: RequiredIf, Options: []string{"proxy_port"}, If: "proxy"},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{"--host=a", ""},
+		{"--socket=/tmp/s --json --cert=c --key=k", ""},
+		{"--host=a --auth=basic --user=u --password=p", ""},
+		{"--host=a --proxy=p --proxy_port=8080", ""},
+		{"", "Exactly one of --host or --socket must be provided"},
+		{"--host=a --socket=b", "Only one of --host or --socket may be provided, got --host and --socket"},
+		{"--host=a --json --yaml", "Only one of --json or --yaml may be provided, got --json and --yaml"},
+		{"--host=a --key=k", "--cert and --key must be provided together, missing --cert"},
+		{"--host=a --auth=basic", "--user and --password are required when --auth is basic, missing --user and --password"},
+		{"--host=a --proxy=p", "--proxy_port is required when --proxy is provided, missing --proxy_port"},
+	}
+
+	t.Setenv("ART_TEST_PASSWORD", "")
+	t.Setenv("ART_TEST_JSON", "")
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("Input %q unexpected error %v", c.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.err {
+			t.Errorf("Input %q expected error %q, got %v", c.input, c.err, err)
+		}
+	}
+
+	// Options provided by environment variable count towards groups
+	t.Setenv("ART_TEST_PASSWORD", "secret")
+	tokens, _ := parse("--host=a --auth=basic --user=u")
+	if err := cmd.Execute(tokens, nil, false); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Options with an implicit value which are set false by environment variable aren't provided
+	t.Setenv("ART_TEST_JSON", "false")
+	tokens, _ = parse("--host=a --yaml")
+	if err := cmd.Execute(tokens, nil, false); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Options provided by environment variable don't conflict with those on the command line
+	t.Setenv("ART_TEST_JSON", "true")
+	tokens, _ = parse("--host=a --yaml")
+	if err := cmd.Execute(tokens, nil, false); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	tokens, _ = parse("--host=a --json --yaml")
+	if err := cmd.Execute(tokens, nil, false); err == nil || err.Error() != "Only one of --json or --yaml may be provided, got --json and --yaml" {
+		t.Errorf("Expected --json and --yaml to conflict, got %v", err)
+	}
+}
+
+func TestCommandOptionGroupsValidation(t *testing.T) {
+	cases := []*OptionGroup{
+		{Options: []string{"host", "socket"}},
+		{Kind: ExactlyOneOf, Options: []string{"host"}},
+		{Kind: AtMostOneOf, Options: []string{"host", "port"}},
+		{Kind: AllOrNone, Options: []string{"host", "host"}},
+		{Kind: AllOrNone, Options: []string{"host", "socket"}, If: "host"},
+		{Kind: RequiredIf, Options: []string{"host"}, If: "missing"},
+		{Kind: RequiredIf, Options: []string{"host"}, If: "socket", Equals: 5},
+		{Kind: RequiredIf, Options: []string{"host"}, If: "host"},
+	}
+
+	for _, group := range cases {
+		cmd := &Command{
+			Name:        "connect",
+			Description: "connect to a server",
+			Options: []*Option{
+				{Name: "host", Description: "host name"},
+				{Name: "socket", Description: "unix socket"},
+			},
+			OptionGroups: []*OptionGroup{group},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return nil
+			},
+		}
+		if err := cmd.Prepare(); err == nil {
+			t.Errorf("Group %+v expected a validation error", group)
+		}
+	}
+}
+
+func TestCommandOptionGroupsHelp(t *testing.T) {
+	cmd := Command{
+		Name:        "connect",
+		Description: "connect to a server",
+		Options: []*Option{
+			{Name: "host", Description: "host name"},
+			{Name: "socket", Description: "unix socket"},
+			{Name: "json", Description: "json output", Value: true, EnvVar: "ART_TEST_JSON"},
+			{Name: "yaml", Description: "yaml output", Value: true},
+			{Name: "cert", Description: "client certificate"},
+			{Name: "key", Description: "client key"},
+			{Name: "auth", Description: "authentication method", Default: "none"},
+			{Name: "user", Description: "user name"},
+			{Name: "password", Description: "password", EnvVar: "ART_TEST_PASSWORD"},
+			{Name: "proxy", Description: "proxy address"},
+			{Name: "proxy_port", Description: "proxy port", Type: Int},
+		},
+		OptionGroups: []*OptionGroup{
+			{Kind: ExactlyOneOf, Options: []string{"host", "socket"}},
+			{Kind: AtMostOneOf, Options: []string{"json", "yaml"}},
+			{Kind: AllOrNone, Options: []string{"cert", "key"}},
+			{Kind: RequiredIf, Options: []string{"user", "password"}, If: "auth", Equals: "basic"},
+			{Kind: RequiredIf, Options: []string{"proxy_port"}, If: "proxy"},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	cmd.WriteHelp(buf)
+	for _, expected := range []string{
+		"option rules:",
+		"exactly one of --host or --socket",
+		"at most one of --json or --yaml",
+		"all or none of --cert and --key",
+		"--user and --password are required when --auth is basic",
+		"--proxy_port is required when --proxy is provided",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected help to contain %q\n%s", expected, buf.String())
+		}
+	}
+}
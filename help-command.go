@@ -41,8 +41,9 @@ func makeHelpCommand() *Command {
 				return err
 			}
 
+			w := processor.Stdout()
 			if len(helpArgs.Command) == 0 {
-				fmt.Println()
+				fmt.Fprintln(w)
 				groups := []string{}
 				byGroup := map[string][]*Command{}
 				for _, cmd := range processor.commandLookup {
@@ -75,27 +76,40 @@ func makeHelpCommand() *Command {
 							groupName = processor.DefaultHeading
 						}
 					}
-					tg.Print(tg.Bold, tg.Blue, groupName, "\n\n", tg.Reset)
+					tg.Fprint(w, tg.Bold, tg.Blue, groupName, "\n\n", tg.Reset)
 					table := tg.NewTable("command", "description")
 					table.HideHeading = true
 					for _, cmd := range group {
 						table.Append(cmd.Name, cmd.Description)
 					}
-					table.Render()
-					fmt.Println()
+					table.RenderTo(w)
+					fmt.Fprintln(w)
+				}
+
+				if aliases := processor.aliasNames(); len(aliases) > 0 {
+					tg.Fprint(w, tg.Bold, tg.Blue, "aliases", "\n\n", tg.Reset)
+					table := tg.NewTable("alias", "expansion")
+					table.HideHeading = true
+					for _, name := range aliases {
+						expansion, _ := processor.Alias(name)
+						table.Append(name, expansion)
+					}
+					table.RenderTo(w)
+					fmt.Fprintln(w)
 				}
 			} else {
-				var curCommand *Command
-				var ok bool
-				curLookup := processor.commandLookup
-				for _, cmdName := range helpArgs.Command {
-					curCommand, ok = curLookup[cmdName]
-					if !ok {
-						return fmt.Errorf("unknown command or subcommand \"%s\"", cmdName)
+				if _, exists := processor.commandLookup[helpArgs.Command[0]]; !exists {
+					if expansion, ok := processor.Alias(helpArgs.Command[0]); ok {
+						fmt.Fprintf(w, "%s is an alias for %s\n", helpArgs.Command[0], expansion)
+						return nil
 					}
-					curLookup = curCommand.subCommandLookup
 				}
-				curCommand.DisplayHelp()
+
+				curCommand, err := processor.lookupCommandPath(helpArgs.Command)
+				if err != nil {
+					return err
+				}
+				curCommand.WriteHelp(w)
 			}
 
 			return nil
@@ -105,7 +119,8 @@ func makeHelpCommand() *Command {
 			before := processor.beforeAndCursor[5:]
 			full := processor.full[5:]
 
-			return processor.OnComplete(before, processor.afterCursor, full)
+			sug, _ := processor.onComplete(before, processor.afterCursor, full, processor.activeAliases)
+			return sug
 		},
 	}
 }
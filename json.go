@@ -0,0 +1,101 @@
+package artillery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseJSON parses an inline JSON document, or the contents of a file when the value is @path
+func parseJSON(value string) (any, error) {
+	data := []byte(value)
+	source := "JSON"
+	if strings.HasPrefix(value, "@") {
+		path := expandHome(value[1:])
+		if path == "" {
+			return nil, fmt.Errorf("expected a JSON document or @file.json")
+		}
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s - %s", path, unwrapPathError(err))
+		}
+		source = path
+	}
+
+	var result any
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := jsonPosition(data, syntaxErr.Offset)
+			return nil, fmt.Errorf("invalid %s at line %d, column %d - %s", source, line, col, syntaxErr)
+		}
+		return nil, fmt.Errorf("invalid %s - %s", source, err)
+	}
+	return result, nil
+}
+
+// unwrapPathError removes the operation and path from a filesystem error, which are already in the message
+func unwrapPathError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
+
+// jsonPosition converts the offset of a JSON syntax error, which is the number of bytes read when the error
+// occurred, into the 1 based line and column of the offending character
+func jsonPosition(data []byte, offset int64) (int, int) {
+	pos := int(offset) - 1
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(data) {
+		pos = len(data)
+	}
+	before := data[:pos]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+func completeJSON(prefix string, processor *Processor) []string {
+	if !strings.HasPrefix(prefix, "@") {
+		return []string{}
+	}
+	results := completeFiles(prefix[1:], false)
+	for idx, result := range results {
+		results[idx] = "@" + result
+	}
+	return results
+}
+
+// isJSONValue returns true for the composite values produced by the JSON type
+func isJSONValue(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertJSON converts a JSON value into the target type by encoding it and decoding it again
+func convertJSON(value any, target reflect.Type) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.New(target)
+	err = json.Unmarshal(data, ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
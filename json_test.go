@@ -0,0 +1,159 @@
+package artillery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	val, err := parseJSON(`{"name": "web", "ports": [80, 443]}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	doc, ok := val.(map[string]any)
+	if !ok || doc["name"] != "web" || len(doc["ports"].([]any)) != 2 {
+		t.Errorf("Unexpected document %v", val)
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`{"name" "web"}`, "invalid JSON at line 1, column 9"},
+		{"{\n  \"a\": 1,\n  }", "invalid JSON at line 3, column 3"},
+		{`{"a": 1} x`, "invalid JSON at line 1, column 10"},
+		{`{"a": `, "invalid JSON at line 1, column 6"},
+		{"@", "expected a JSON document"},
+	}
+	for _, c := range cases {
+		_, err := parseJSON(c.input)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("Input %q expected error containing %q, got %v", c.input, c.err, err)
+		}
+	}
+}
+
+func TestParseJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "payload.json")
+	err := os.WriteFile(path, []byte("{\n  \"replicas\": 3\n}\n"), 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	val, err := parseJSON("@" + path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if val.(map[string]any)["replicas"] != float64(3) {
+		t.Errorf("Unexpected document %v", val)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	err = os.WriteFile(bad, []byte("{\n  \"replicas\": three\n}\n"), 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = parseJSON("@" + bad)
+	if err == nil || !strings.Contains(err.Error(), "invalid "+bad+" at line 2, column 16") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	_, err = parseJSON("@" + filepath.Join(dir, "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), "unable to read") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	results := completeJSON("@"+dir+"/pay", nil)
+	if len(results) != 1 || results[0] != "@"+path {
+		t.Errorf("Unexpected completions %v", results)
+	}
+}
+
+func TestCommandJSONReflect(t *testing.T) {
+	type container struct {
+		Image string
+		Ports []int
+	}
+	var result struct {
+		Spec    container
+		Raw     any
+		Extra   *container
+		Volumes []container
+	}
+	cmd := Command{
+		Name:        "apply",
+		Description: "apply a specification",
+		Arguments: []*Argument{
+			{
+				Name:        "spec",
+				Description: "container specification",
+				Type:        JSON,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "raw",
+				Description: "raw document",
+				Type:        JSON,
+			},
+			{
+				Name:        "extra",
+				Description: "extra container",
+				Type:        JSON,
+			},
+			{
+				Name:        "volumes",
+				Description: "volumes",
+				Type:        JSON,
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse(`--raw '[1, "two"]' --extra='{"image": "redis"}' --volumes '{"image": "a"}' --volumes '{"image": "b"}' '{"image": "nginx", "ports": [80, 443]}'`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if result.Spec.Image != "nginx" || len(result.Spec.Ports) != 2 || result.Spec.Ports[1] != 443 {
+		t.Errorf("Unexpected spec %+v", result.Spec)
+	}
+	if raw, ok := result.Raw.([]any); !ok || len(raw) != 2 || raw[1] != "two" {
+		t.Errorf("Unexpected raw %v", result.Raw)
+	}
+	if result.Extra == nil || result.Extra.Image != "redis" {
+		t.Errorf("Unexpected extra %v", result.Extra)
+	}
+	if len(result.Volumes) != 2 || result.Volumes[1].Image != "b" {
+		t.Errorf("Unexpected volumes %v", result.Volumes)
+	}
+
+	var mismatch struct {
+		Spec container
+	}
+	err = Reflect(Namespace{"spec": map[string]any{"ports": "many"}}, &mismatch)
+	if err == nil || !strings.Contains(err.Error(), "Cannot assign JSON value of spec") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
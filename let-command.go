@@ -0,0 +1,57 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashibuto/artillery/pkg/tg"
+)
+
+func makeLetCommand() *Command {
+	return &Command{
+		Name:        "let",
+		Description: "assign shell variables, or list them when no assignment is given",
+		Arguments: []*Argument{
+			{
+				Name:        "assignment",
+				Description: "assignment in the form of name=value",
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			var args struct {
+				Assignment []string
+			}
+			err := Reflect(ns, &args)
+			if err != nil {
+				return err
+			}
+
+			if len(args.Assignment) == 0 {
+				table := tg.NewTable("name", "value")
+				table.HideHeading = true
+				if processor.variables != nil {
+					for _, name := range processor.variables.names() {
+						value, _ := processor.variables.get(name)
+						table.Append(name, value)
+					}
+				}
+				table.RenderTo(processor.Stdout())
+				return nil
+			}
+
+			for _, assignment := range args.Assignment {
+				name, value, ok := strings.Cut(assignment, "=")
+				if !ok {
+					return fmt.Errorf("Expected an assignment in the form of name=value, got \"%s\"", assignment)
+				}
+				err = processor.SetVariable(name, value)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package artillery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DuplicateKeys determines what happens when a key is supplied more than once to a map option
+type DuplicateKeys int
+
+const (
+	DuplicateError     DuplicateKeys = iota // Supplying a key more than once is an error
+	DuplicateOverwrite                      // The last value supplied for a key wins
+)
+
+// CreateEmptyMapOfType returns an empty map of string keys to values of the registered type, or to strings
+// when the type is unknown
+func CreateEmptyMapOfType(valueType ArgType) any {
+	rt, ok := lookupType(valueType)
+	if !ok {
+		return map[string]string{}
+	}
+	return reflect.MakeMap(reflect.MapOf(reflect.TypeOf(""), rt.elemType)).Interface()
+}
+
+// splitPairs splits a comma separated list of key=value pairs, ie. a=1,b=2
+func splitPairs(value string) ([][2]string, error) {
+	pairs := [][2]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value pairs, ie. env=prod or a=1,b=2")
+		}
+		pairs = append(pairs, [2]string{key, val})
+	}
+	return pairs, nil
+}
+
+// setMapValue stores a converted value in a map created by CreateEmptyMapOfType, according to the duplicate
+// key policy
+func setMapValue(m any, key string, value any, duplicates DuplicateKeys) error {
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		return fmt.Errorf("cannot store a value in %T", m)
+	}
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || !val.Type().AssignableTo(mv.Type().Elem()) {
+		return fmt.Errorf("parsed %T value cannot be stored in %T", value, m)
+	}
+
+	k := reflect.ValueOf(key)
+	if duplicates == DuplicateError && mv.MapIndex(k).IsValid() {
+		return fmt.Errorf("key \"%s\" was supplied more than once", key)
+	}
+	mv.SetMapIndex(k, val)
+	return nil
+}
+
+// mapLength returns the number of keys in a map value, or 0 if the value isn't a map
+func mapLength(m any) int {
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		return 0
+	}
+	return mv.Len()
+}
@@ -0,0 +1,120 @@
+package artillery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandMapOption(t *testing.T) {
+	var result struct {
+		Label map[string]string
+		Limit map[string]int
+	}
+	cmd := Command{
+		Name:        "deploy",
+		Description: "deploy a resource",
+		Options: []*Option{
+			{
+				Name:        "label",
+				Description: "labels to apply",
+				ShortName:   'l',
+				IsMap:       true,
+			},
+			{
+				Name:          "limit",
+				Description:   "resource limits",
+				Type:          Int,
+				IsMap:         true,
+				DuplicateKeys: DuplicateOverwrite,
+				Min:           0,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("--label env=prod -l team=core,url=http://x?a=b --limit cpu=2,mem=512 --limit=cpu=4")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(result.Label) != 3 || result.Label["env"] != "prod" || result.Label["team"] != "core" || result.Label["url"] != "http://x?a=b" {
+		t.Errorf("Unexpected labels %v", result.Label)
+	}
+	if len(result.Limit) != 2 || result.Limit["cpu"] != 4 || result.Limit["mem"] != 512 {
+		t.Errorf("Unexpected limits %v", result.Limit)
+	}
+	if display := cmd.Options[0].InvocationDisplay(); display != "-l, --label=<key>=<string>" {
+		t.Errorf("Unexpected display %s", display)
+	}
+}
+
+func TestCommandMapOptionErrors(t *testing.T) {
+	cmd := Command{
+		Name:        "deploy",
+		Description: "deploy a resource",
+		Options: []*Option{
+			{
+				Name:        "label",
+				Description: "labels to apply",
+				ShortName:   'l',
+				IsMap:       true,
+			},
+			{
+				Name:          "limit",
+				Description:   "resource limits",
+				Type:          Int,
+				IsMap:         true,
+				DuplicateKeys: DuplicateOverwrite,
+				Min:           0,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{"--label env=prod --label env=dev", "key \"env\" was supplied more than once"},
+		{"--label env", "expected key=value pairs"},
+		{"--label =prod", "expected key=value pairs"},
+		{"--limit cpu=lots", "expected an integer value"},
+		{"--limit cpu=-1", "must be at least 0"},
+	}
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("Input %q expected error containing %q, got %v", c.input, c.err, err)
+		}
+	}
+
+	opt := &Option{Name: "label", Description: "labels", IsMap: true, IsArray: true}
+	if err := opt.Validate(); err == nil {
+		t.Error("Expected IsMap with IsArray to be rejected")
+	}
+}
@@ -0,0 +1,78 @@
+package artillery
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HostAndPort is the value of the HostPort type
+type HostAndPort struct {
+	Host string
+	Port int
+}
+
+// String returns the host and port joined as host:port, with IPv6 hosts in brackets
+func (hp HostAndPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+func parseIP(value string) (any, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("expected an IP address such as 192.168.0.1 or ::1")
+	}
+	return ip, nil
+}
+
+func parseCIDR(value string) (any, error) {
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected a network in CIDR notation such as 10.0.0.0/8")
+	}
+	return ipNet, nil
+}
+
+func parseHostPort(value string) (any, error) {
+	host, portStr, err := net.SplitHostPort(value)
+	if err != nil || host == "" {
+		return nil, fmt.Errorf("expected a host and port such as localhost:8080 or [::1]:8080")
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("expected a port between 1 and 65535")
+	}
+	return HostAndPort{Host: host, Port: port}, nil
+}
+
+func parseURL(value string) (any, error) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "" && u.Path == "") {
+		return nil, fmt.Errorf("expected an absolute URL such as https://example.com")
+	}
+	return u, nil
+}
+
+// validateSchemes ensures that schemes are only restricted for the URL type
+func validateSchemes(schemes []string, argType ArgType) error {
+	if len(schemes) > 0 && argType != URL {
+		return fmt.Errorf("Schemes may only be used with the url type")
+	}
+	return nil
+}
+
+// checkScheme returns an error when the value is a URL whose scheme isn't one of the permitted schemes
+func checkScheme(val any, schemes []string) error {
+	u, ok := val.(*url.URL)
+	if !ok || len(schemes) == 0 {
+		return nil
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a URL with a scheme of %s", strings.Join(schemes, ", "))
+}
@@ -0,0 +1,138 @@
+package artillery
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestConvertNetwork(t *testing.T) {
+	cases := []struct {
+		value    string
+		argType  ArgType
+		expected string
+		isError  bool
+	}{
+		{"192.168.0.1", IP, "192.168.0.1", false},
+		{"::1", IP, "::1", false},
+		{"192.168.0.256", IP, "", true},
+		{"10.1.2.3/8", CIDR, "10.0.0.0/8", false},
+		{"fd00::/64", CIDR, "fd00::/64", false},
+		{"10.0.0.0", CIDR, "", true},
+		{"localhost:8080", HostPort, "localhost:8080", false},
+		{"[::1]:443", HostPort, "[::1]:443", false},
+		{"localhost", HostPort, "", true},
+		{":8080", HostPort, "", true},
+		{"localhost:http", HostPort, "", true},
+		{"localhost:70000", HostPort, "", true},
+		{"https://example.com/a?b=c", URL, "https://example.com/a?b=c", false},
+		{"mailto:someone@example.com", URL, "mailto:someone@example.com", false},
+		{"example.com", URL, "", true},
+		{"https://", URL, "", true},
+	}
+
+	for _, c := range cases {
+		val, err := convert(c.value, c.argType)
+		if (err != nil) != c.isError {
+			t.Errorf("Value %q as %s expected error %v, got %v", c.value, c.argType, c.isError, err)
+			continue
+		}
+		if c.isError {
+			if !strings.HasPrefix(err.Error(), "expected") {
+				t.Errorf("Value %q as %s expected a descriptive error, got %v", c.value, c.argType, err)
+			}
+			continue
+		}
+		if str := val.(interface{ String() string }).String(); str != c.expected {
+			t.Errorf("Value %q as %s expected %q, got %q", c.value, c.argType, c.expected, str)
+		}
+	}
+}
+
+func TestCommandNetworkTypes(t *testing.T) {
+	var result struct {
+		Targets  []net.IP
+		Allow    *net.IPNet
+		Listen   HostAndPort
+		Endpoint *url.URL
+	}
+	cmd := Command{
+		Name:        "probe",
+		Description: "probe the targets",
+		Arguments: []*Argument{
+			{
+				Name:        "targets",
+				Description: "addresses to probe",
+				Type:        IP,
+				IsArray:     true,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "allow",
+				Description: "network permitted to respond",
+				Type:        CIDR,
+			},
+			{
+				Name:        "listen",
+				Description: "address to listen on",
+				Type:        HostPort,
+			},
+			{
+				Name:        "endpoint",
+				Description: "endpoint to report to",
+				Type:        URL,
+				Schemes:     []string{"http", "https"},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, _ := parse("--allow=10.0.0.0/8 --listen=:8080 10.0.0.1")
+	if err = cmd.Execute(tokens, nil, false); err == nil {
+		t.Error("Expected an error for a listen address without a host")
+	}
+
+	tokens, _ = parse("--endpoint=ftp://example.com 10.0.0.1")
+	if err = cmd.Execute(tokens, nil, false); err == nil || !strings.Contains(err.Error(), "http, https") {
+		t.Errorf("Expected a scheme error, got %v", err)
+	}
+
+	tokens, _ = parse("--allow 10.0.0.0/8 --listen 0.0.0.0:8080 --endpoint HTTPS://example.com 10.0.0.1 ::1")
+	if err = cmd.Execute(tokens, nil, false); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(result.Targets) != 2 || !result.Targets[1].Equal(net.IPv6loopback) {
+		t.Errorf("Unexpected targets %v", result.Targets)
+	}
+	if result.Allow == nil || !result.Allow.Contains(result.Targets[0]) {
+		t.Errorf("Unexpected allowed network %v", result.Allow)
+	}
+	if result.Listen.Port != 8080 || result.Listen.Host != "0.0.0.0" {
+		t.Errorf("Unexpected listen address %v", result.Listen)
+	}
+	if result.Endpoint == nil || result.Endpoint.Host != "example.com" {
+		t.Errorf("Unexpected endpoint %v", result.Endpoint)
+	}
+	if display := cmd.Options[2].InvocationDisplay(); display != "--listen=<host:port>" {
+		t.Errorf("Expected display --listen=<host:port>, got %s", display)
+	}
+
+	broken := &Option{
+		Name:        "path",
+		Description: "not a url",
+		Schemes:     []string{"https"},
+	}
+	if err = broken.Validate(); err == nil {
+		t.Error("Expected an error using Schemes without the url type")
+	}
+}
@@ -2,32 +2,44 @@ package artillery
 
 import (
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// CreateEmptyArrayOfType returns an empty slice of the registered type, or of strings when the type is unknown
 func CreateEmptyArrayOfType(arrType ArgType) any {
-	switch arrType {
-	case String:
-		return []string{}
-	case Int:
-		return []int{}
-	case Float:
-		return []float64{}
-	case Bool:
-		return []bool{}
-	default:
+	rt, ok := lookupType(arrType)
+	if !ok {
 		return []string{}
 	}
+	return reflect.MakeSlice(reflect.SliceOf(rt.elemType), 0, 0).Interface()
 }
 
 type Option struct {
-	ShortName   byte
-	Name        string
-	Description string
-	Type        ArgType
-	Value       any // When value is specified, the option has an implicit value and cannot be provided with --opt=value
-	Default     any
-	IsArray     bool // When true, argument can be reused multiple times
-	IsRequired  bool // When true a value is required to be set
+	ShortName     byte
+	Name          string
+	Description   string
+	Type          ArgType
+	Value         any // When value is specified, the option has an implicit value and cannot be provided with --opt=value
+	Default       any
+	IsArray       bool                  // When true, argument can be reused multiple times
+	IsCount       bool                  // When true, the option takes no value and counts the number of times it is used, ie. -vvv is 3
+	IsMap         bool                  // When true, values are key=value pairs collected into a map of string keys to the type, ie. --label env=prod,team=core
+	DuplicateKeys DuplicateKeys         // Determines whether a key supplied more than once to a map is an error or overwrites
+	IsRequired    bool                  // When true a value is required to be set
+	EnvVar        string                // Environment variable read when the option isn't supplied on the command line, before falling back to Default
+	PathCheck     PathCheck             // Checks applied to values of the path, file and dir types
+	Schemes       []string              // Schemes permitted for values of the url type, any scheme when empty
+	Min           any                   // Inclusive minimum for numeric, duration and time values
+	Max           any                   // Inclusive maximum for numeric, duration and time values
+	MinLen        int                   // Minimum length of string values
+	MaxLen        int                   // Maximum length of string values, unlimited when 0
+	Pattern       string                // Regular expression which string values must match in their entirety
+	MinCount      int                   // Minimum number of values when IsArray is true, or keys when IsMap is true
+	MaxCount      int                   // Maximum number of values when IsArray is true, or keys when IsMap is true, unlimited when 0
+	ValidateFunc  func(value any) error // Checks each converted value
 }
 
 // Validate ensures the validity of the option
@@ -51,6 +63,32 @@ func (opt *Option) Validate() error {
 		return fmt.Errorf("Option must have a description")
 	}
 
+	err := opt.constraints().validate()
+	if err != nil {
+		return err
+	}
+
+	if opt.IsCount {
+		if opt.Type != "" && opt.Type != Int {
+			return fmt.Errorf("IsCount options must be of the int type")
+		}
+		if opt.IsArray || opt.IsMap || opt.Value != nil {
+			return fmt.Errorf("IsCount options cannot be used with IsArray, IsMap or an implicit Value")
+		}
+		if _, ok := opt.Default.(int); opt.Default != nil && !ok {
+			return fmt.Errorf("Default value of an IsCount option must be an int")
+		}
+	}
+
+	if opt.IsMap {
+		if opt.IsArray {
+			return fmt.Errorf("IsMap and IsArray cannot be used together")
+		}
+		if opt.Value != nil {
+			return fmt.Errorf("IsMap options cannot have an implicit Value")
+		}
+	}
+
 	if opt.Value != nil {
 		switch opt.Value.(type) {
 		case int:
@@ -58,7 +96,10 @@ func (opt *Option) Validate() error {
 		case bool:
 		case float64:
 		default:
-			return fmt.Errorf("Default value must be one of int, string, bool, or float64 types")
+			// Otherwise the value must be of the option's own type
+			if rt, _ := lookupType(opt.Type); reflect.TypeOf(opt.Value) != rt.elemType {
+				return fmt.Errorf("Default value must be one of int, string, bool, float64 or %s types", typeDisplay(opt.Type))
+			}
 		}
 	}
 
@@ -69,28 +110,26 @@ func (opt *Option) Validate() error {
 func (opt *Option) ApplyDefault(namespace Namespace) {
 	if opt.IsArray {
 		namespace[opt.Name] = CreateEmptyArrayOfType(opt.Type)
+	} else if opt.IsMap {
+		namespace[opt.Name] = CreateEmptyMapOfType(opt.Type)
+	} else if opt.IsCount && opt.Default == nil {
+		namespace[opt.Name] = 0
 	} else {
 		namespace[opt.Name] = opt.Default
 	}
 }
 
-// ApplyArrayDefaults applies array defaults to the target if empty after processing
+// ApplyArrayDefaults applies array and map defaults to the target if empty after processing
 func (opt *Option) ApplyArrayDefaults(namespace Namespace) {
 	if opt.IsArray {
 		val := namespace[opt.Name]
-		var length int
-		switch t := val.(type) {
-		case []string:
-			length = len(t)
-		case []int:
-			length = len(t)
-		case []float64:
-			length = len(t)
-		case []bool:
-			length = len(t)
-		}
-
-		if length == 0 && opt.Default != nil {
+		if arrayLength(val) == 0 && opt.Default != nil {
+			namespace[opt.Name] = opt.Default
+		}
+	}
+	if opt.IsMap {
+		val := namespace[opt.Name]
+		if mapLength(val) == 0 && opt.Default != nil {
 			namespace[opt.Name] = opt.Default
 		}
 	}
@@ -103,21 +142,64 @@ func (opt *Option) Apply(inp *OptionInput, namespace Namespace) error {
 			return fmt.Errorf("Value must be specified for option %s", opt.InvocationDisplay())
 		}
 
-		val, err := convert(inp.Value, opt.Type)
+		val, err := opt.convert(inp.Value)
+		if err != nil {
+			return err
+		}
+
+		arr, err := appendValue(namespace[opt.Name], val)
 		if err != nil {
 			return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
 		}
+		namespace[opt.Name] = arr
+		return nil
+	}
+
+	if opt.IsMap {
+		if inp.Value == "" {
+			return fmt.Errorf("Value must be specified for option %s", opt.InvocationDisplay())
+		}
+
+		pairs, err := splitPairs(inp.Value)
+		if err != nil {
+			return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+		}
+		for _, pair := range pairs {
+			val, err := opt.convert(pair[1])
+			if err != nil {
+				return err
+			}
+			err = setMapValue(namespace[opt.Name], pair[0], val, opt.DuplicateKeys)
+			if err != nil {
+				return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			}
+		}
+		return nil
+	}
+
+	if opt.IsCount {
+		if inp.Value != "" {
+			return fmt.Errorf("Option %s does not accept an \"=\" assigment operator", opt.InvocationDisplay())
+		}
+		count, _ := namespace[opt.Name].(int)
+		err := opt.constraints().check(count + 1)
+		if err != nil {
+			return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+		}
+		namespace[opt.Name] = count + 1
+		return nil
+	}
 
-		switch t := namespace[opt.Name].(type) {
-		case []string:
-			namespace[opt.Name] = append(t, val.(string))
-		case []int:
-			namespace[opt.Name] = append(t, val.(int))
-		case []float64:
-			namespace[opt.Name] = append(t, val.(float64))
-		case []bool:
-			namespace[opt.Name] = append(t, val.(bool))
+	if opt.isNegation(inp.Name) {
+		if inp.Value != "" {
+			return fmt.Errorf("Option --no-%s does not accept an \"=\" assigment operator", opt.Name)
 		}
+		namespace[opt.Name] = false
+		return nil
+	}
+
+	if opt.negatable() && inp.Value == "" {
+		namespace[opt.Name] = true
 		return nil
 	}
 
@@ -135,9 +217,9 @@ func (opt *Option) Apply(inp *OptionInput, namespace Namespace) error {
 			return fmt.Errorf("Option %s must specify a value by use of an \"=\" assigment operator", opt.InvocationDisplay())
 		}
 
-		val, err := convert(inp.Value, opt.Type)
+		val, err := opt.convert(inp.Value)
 		if err != nil {
-			return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			return err
 		}
 
 		namespace[opt.Name] = val
@@ -146,33 +228,144 @@ func (opt *Option) Apply(inp *OptionInput, namespace Namespace) error {
 	return nil
 }
 
+// ApplyEnv applies the value of the option's environment variable to the namespace, returning false when the
+// variable is empty or unset.  Arrays are read as comma separated values, and options with an implicit Value
+// are applied when the variable is true.
+func (opt *Option) ApplyEnv(namespace Namespace) (bool, error) {
+	if opt.EnvVar == "" {
+		return false, nil
+	}
+	value := os.Getenv(opt.EnvVar)
+	if value == "" {
+		return false, nil
+	}
+
+	values := []string{value}
+	if opt.IsArray {
+		values = strings.Split(value, ",")
+	}
+	err := opt.applyValues(values, namespace)
+	if err != nil {
+		return false, fmt.Errorf("%s (from environment variable %s)", err, opt.EnvVar)
+	}
+	return true, nil
+}
+
+// applyValues applies values supplied from outside of the command line, such as by environment variable or
+// config file.  Each value is applied as though the option had been repeated, except that counts are set
+// directly, and options with an implicit Value are set to it when the value is true, or to false (the zero
+// value of non-bool options) when it's false.
+func (opt *Option) applyValues(values []string, namespace Namespace) error {
+	for _, value := range values {
+		switch {
+		case opt.IsCount:
+			count, err := convert(value, Int)
+			if err == nil {
+				err = opt.constraints().check(count)
+			}
+			if err != nil {
+				return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			}
+			namespace[opt.Name] = count
+		case opt.Value != nil:
+			set, err := parseBool(value)
+			if err != nil {
+				return fmt.Errorf("Option %s - %s", opt.InvocationDisplay(), err)
+			}
+			if set == true {
+				namespace[opt.Name] = opt.Value
+			} else {
+				namespace[opt.Name] = reflect.Zero(reflect.TypeOf(opt.Value)).Interface()
+			}
+		default:
+			err := opt.Apply(&OptionInput{Name: opt.Name, Value: value}, namespace)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// helpDescription returns the description shown in help, including constraints and the environment variable
+func (opt *Option) helpDescription() string {
+	desc := opt.constraints().describeWith(opt.Description)
+	if opt.EnvVar != "" {
+		desc = fmt.Sprintf("%s [env %s]", desc, opt.EnvVar)
+	}
+	return desc
+}
+
+// convert converts a value supplied for the option to its type
+func (opt *Option) convert(value string) (any, error) {
+	val, err := convert(value, opt.argType())
+	if err == nil {
+		err = validateValue(val, opt.constraints(), opt.ValidateFunc)
+	}
+	if err != nil {
+		return nil, &ValidationError{Token: value, Name: opt.Name, Err: err, prefix: "Option " + opt.InvocationDisplay()}
+	}
+	return val, nil
+}
+
+// negatable returns true when the option may be set to false with --no-<name>, which is the case for bool
+// options which default to true.  Such an option is set true by --<name> alone.
+func (opt *Option) negatable() bool {
+	return opt.Type == Bool && opt.Default == true && !opt.IsArray && !opt.IsMap
+}
+
+// isNegation returns true when the name used on the command line is the negated form of the option
+func (opt *Option) isNegation(name string) bool {
+	return opt.negatable() && name == "no-"+opt.Name
+}
+
+// takesValue returns true when the option must be supplied with a value, either with "=" or as the following
+// token
+func (opt *Option) takesValue() bool {
+	return opt.Value == nil && !opt.IsCount && !opt.negatable()
+}
+
+// argType returns the type of the option's values
+func (opt *Option) argType() ArgType {
+	if opt.IsCount {
+		return Int
+	}
+	return opt.Type
+}
+
 // InvocationDisplay returns the help name for the option
 func (opt *Option) InvocationDisplay() string {
+	name := opt.Name
 	extra := ""
-	if opt.Default != nil {
+	switch {
+	case opt.IsCount:
+		// Counts are repeated rather than assigned
+	case opt.negatable():
+		name = "[no-]" + opt.Name
+	case opt.IsMap:
+		extra = fmt.Sprintf("=<key>=<%s>", opt.ArgTypeDisplay())
+	case opt.Default != nil:
 		extra = fmt.Sprintf("=%s", opt.DefaultValueDisplay())
-	} else {
+	default:
 		extra = fmt.Sprintf("=<%s>", opt.ArgTypeDisplay())
 	}
 
 	if opt.ShortName != 0 {
-		return fmt.Sprintf("-%s, --%s%s", string(opt.ShortName), opt.Name, extra)
+		return fmt.Sprintf("-%s, --%s%s", string(opt.ShortName), name, extra)
 	}
-	return fmt.Sprintf("--%s%s", opt.Name, extra)
+	return fmt.Sprintf("--%s%s", name, extra)
 }
 
 // ArgTypeDisplay returns the argument data type for display
 func (opt *Option) ArgTypeDisplay() string {
-	switch opt.Type {
-	case "":
-		return string(String)
-	default:
-		return string(opt.Type)
-	}
+	return typeDisplay(opt.argType())
 }
 
 // DefaultValueDisplay returns the default value for display purposes
 func (opt *Option) DefaultValueDisplay() string {
+	if size, ok := opt.Default.(int64); ok && opt.Type == Bytes {
+		return formatBytes(size)
+	}
 	switch t := opt.Default.(type) {
 	case string:
 		return fmt.Sprintf("'%s'", t)
@@ -185,6 +378,10 @@ func (opt *Option) DefaultValueDisplay() string {
 			return "true"
 		}
 		return "false"
+	case time.Duration:
+		return t.String()
+	case time.Time:
+		return t.Format(time.RFC3339)
 	default:
 		return fmt.Sprintf("%v", t)
 	}
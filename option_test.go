@@ -0,0 +1,212 @@
+package artillery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandCountAndNegatedOptions(t *testing.T) {
+	type flags struct {
+		Target  string
+		Verbose int
+		Color   bool
+		Quiet   bool
+	}
+
+	cases := []struct {
+		input    string
+		expected flags
+	}{
+		{"all", flags{Target: "all", Verbose: 0, Color: true}},
+		{"-vvv all", flags{Target: "all", Verbose: 3, Color: true}},
+		{"-v --verbose -q all", flags{Target: "all", Verbose: 2, Color: true, Quiet: true}},
+		{"--no-color all", flags{Target: "all", Color: false}},
+		{"-vq --no-color all", flags{Target: "all", Verbose: 1, Color: false, Quiet: true}},
+		{"--color=false all", flags{Target: "all", Color: false}},
+		{"--no-color --color=true all", flags{Target: "all", Color: true}},
+		{"--no-color --color all", flags{Target: "all", Color: true}},
+		{"--no-color -vc all", flags{Target: "all", Verbose: 1, Color: true}},
+	}
+
+	var result flags
+	cmd := Command{
+		Name:        "build",
+		Description: "build the project",
+		Arguments: []*Argument{
+			{
+				Name:        "target",
+				Description: "build target",
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "verbose",
+				Description: "increase verbosity",
+				ShortName:   'v',
+				IsCount:     true,
+				Max:         3,
+			},
+			{
+				Name:        "color",
+				Description: "colorize output",
+				ShortName:   'c',
+				Type:        Bool,
+				Default:     true,
+			},
+			{
+				Name:        "quiet",
+				Description: "suppress output",
+				ShortName:   'q',
+				Value:       true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			result = flags{}
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if err != nil {
+			t.Errorf("Input %q unexpected error %v", c.input, err)
+			continue
+		}
+		if result != c.expected {
+			t.Errorf("Input %q expected %+v, got %+v", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestCommandCountAndNegatedOptionErrors(t *testing.T) {
+	cmd := Command{
+		Name:        "build",
+		Description: "build the project",
+		Arguments: []*Argument{
+			{
+				Name:        "target",
+				Description: "build target",
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "verbose",
+				Description: "increase verbosity",
+				ShortName:   'v',
+				IsCount:     true,
+				Max:         3,
+			},
+			{
+				Name:        "color",
+				Description: "colorize output",
+				ShortName:   'c',
+				Type:        Bool,
+				Default:     true,
+			},
+			{
+				Name:        "quiet",
+				Description: "suppress output",
+				ShortName:   'q',
+				Value:       true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{"-vvvv all", "must be at most 3"},
+		{"--verbose=2 all", "does not accept an \"=\" assigment operator"},
+		{"--no-color=true all", "does not accept an \"=\" assigment operator"},
+		{"--no-verbose all", "Unknown option no-verbose"},
+		{"--no-quiet all", "Unknown option no-quiet"},
+	}
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("Input %q expected error containing %q, got %v", c.input, c.err, err)
+		}
+	}
+
+	invalid := []*Option{
+		{Name: "verbose", Description: "verbosity", IsCount: true, Type: String},
+		{Name: "verbose", Description: "verbosity", IsCount: true, IsArray: true},
+		{Name: "verbose", Description: "verbosity", IsCount: true, Default: "1"},
+	}
+	for _, opt := range invalid {
+		if err := opt.Validate(); err == nil {
+			t.Errorf("Option %+v expected a validation error", opt)
+		}
+	}
+}
+
+func TestCountAndNegatedInvocationDisplay(t *testing.T) {
+	cmd := Command{
+		Name:        "build",
+		Description: "build the project",
+		Arguments: []*Argument{
+			{
+				Name:        "target",
+				Description: "build target",
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "verbose",
+				Description: "increase verbosity",
+				ShortName:   'v',
+				IsCount:     true,
+				Max:         3,
+			},
+			{
+				Name:        "color",
+				Description: "colorize output",
+				ShortName:   'c',
+				Type:        Bool,
+				Default:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := map[string]string{
+		"color":   "-c, --[no-]color",
+		"verbose": "-v, --verbose",
+	}
+	for _, opt := range cmd.Options {
+		if display, ok := expected[opt.Name]; ok && opt.InvocationDisplay() != display {
+			t.Errorf("Expected %s, got %s", display, opt.InvocationDisplay())
+		}
+	}
+}
@@ -3,28 +3,47 @@ package artillery
 import (
 	"fmt"
 	"regexp"
-	"strconv"
-	"strings"
 )
 
 var validNameChars = "[a-zA-Z0-9_]"
+
+// validLongName allows hyphens within long option names, such as the negated form --no-color
+var validLongName = validNameChars + "[a-zA-Z0-9_-]*"
 var optionParser = regexp.MustCompile(fmt.Sprintf(
-	"(^-(%s)$)|(^-(%s)=(.*)$)|(^-(%s+)$)|(^--(%s+)$)|(^--(%s+)=(.*)$)",
-	validNameChars,
-	validNameChars,
+	"(^-(%s)$)|(^-(%s)=(.*)$)|(^-(%s+)$)|(^--(%s)$)|(^--(%s)=(.*)$)",
 	validNameChars,
 	validNameChars,
 	validNameChars,
+	validLongName,
+	validLongName,
 ))
 
 type OptionInput struct {
 	Name  string
 	Value string
+	index int // Index of the word supplying Value, see argInput
+}
+
+// argInput is a positional token along with the index of the word it was read from, so that the word can be
+// highlighted when its value is rejected.  Index 0 is the command name, which is never a value, so it marks
+// tokens which weren't read from words.  Dash prefixed tokens beginning with a digit are marked, see dashInput.
+type argInput struct {
+	value string
+	index int
+	dash  bool
+}
+
+// plain returns the token without the index of its word
+func (a argInput) plain() any {
+	if a.dash {
+		return dashInput(a.value)
+	}
+	return a.value
 }
 
-// group attempts to group tokens into either options or positional arguments.  group will error if
-// positional arguments precede options.
-func group(tokens []any) ([]*OptionInput, []string, error) {
+// group attempts to group tokens into either options or positional arguments.  Unless interleaved is
+// true, group will error if positional arguments precede options.
+func group(tokens []any, interleaved bool) ([]*OptionInput, []string, error) {
 	options := []*OptionInput{}
 	args := []string{}
 
@@ -34,8 +53,11 @@ func group(tokens []any) ([]*OptionInput, []string, error) {
 		case string:
 			argsStarted = true
 			args = append(args, t)
+		case argInput:
+			argsStarted = true
+			args = append(args, t.value)
 		case *OptionInput:
-			if argsStarted {
+			if argsStarted && !interleaved {
 				return nil, nil, fmt.Errorf("Options must precede positional arguments")
 			}
 			options = append(options, t)
@@ -55,6 +77,9 @@ func extractCommand(tokens []any) (string, []any, error) {
 	}
 
 	cmdStr := tokens[0]
+	if arg, ok := cmdStr.(argInput); ok {
+		cmdStr = arg.plain()
+	}
 	switch t := cmdStr.(type) {
 	case string:
 		return t, tokens[1:], nil
@@ -73,126 +98,117 @@ func parse(cmd string) ([]any, error) {
 	return categorizeTokens(tokens), nil
 }
 
-// categorizeTokens categorizes parsed tokens into options or arguments
-func categorizeTokens(tokens []string) []any {
-	output := []any{}
-	for _, token := range tokens {
-		matches := optionParser.FindAllStringSubmatch(token, -1)
-		if matches != nil {
-			inner := matches[0]
-			// Single character option
-			if inner[2] != "" {
-				output = append(output, &OptionInput{
-					Name: inner[2],
-				})
-			}
-
-			// Single character option with equals
-			if inner[4] != "" {
-				output = append(output, &OptionInput{
-					Name:  inner[4],
-					Value: inner[5],
-				})
-			}
+// dashInput is a dash prefixed token which begins with a digit, such as -5.  It can be read either as a
+// value (ie. a negative number) or as a group of short options, which can only be decided once the
+// command's argument and option types are known.
+type dashInput string
 
-			// Grouped single options
-			if inner[7] != "" {
-				for _, c := range inner[7] {
-					output = append(output, &OptionInput{
-						Name: string(c),
-					})
-				}
-			}
+// categorizeTokens categorizes parsed tokens into options or arguments.  A "--" token ends option
+// processing, and every token which follows it is treated as an argument.
+func categorizeTokens(tokens []string) []any {
+	output := categorizeWords(tokens)
+	for idx, token := range output {
+		if arg, ok := token.(argInput); ok {
+			output[idx] = arg.plain()
+		}
+	}
+	return output
+}
 
-			// Long form option
-			if inner[9] != "" {
-				output = append(output, &OptionInput{
-					Name: inner[9],
-				})
+// categorizeWords categorizes words as categorizeTokens does, but records the index of the word each token
+// was read from, with positional tokens as argInput
+func categorizeWords(words []string) []any {
+	output := []any{}
+	for idx, word := range words {
+		if word == "--" {
+			for offset, remaining := range words[idx+1:] {
+				output = append(output, argInput{value: remaining, index: idx + 1 + offset})
 			}
+			break
+		}
 
-			// Long form option with equals
-			if inner[11] != "" {
-				output = append(output, &OptionInput{
-					Name:  inner[11],
-					Value: inner[12],
-				})
-			}
+		options := categorizeOption(word)
+		if options == nil {
+			output = append(output, argInput{value: word, index: idx})
+		} else if len(word) > 1 && word[1] >= '0' && word[1] <= '9' {
+			output = append(output, argInput{value: word, index: idx, dash: true})
 		} else {
-			output = append(output, token)
+			for _, option := range options {
+				option.(*OptionInput).index = idx
+			}
+			output = append(output, options...)
 		}
 	}
 	return output
 }
 
-// tokenize breaks the command into individual tokens, preserving quoted areas
-func tokenize(cmd string) ([]string, bool) {
-	tokens := []string{}
-	openQuote := false
-	var quoteChar byte
-	tokenStart := 0
-	for i := 0; i < len(cmd); i++ {
-		if cmd[i] == ' ' && !openQuote {
-			token := strings.Trim(cmd[tokenStart:i], " ")
-			if (strings.HasPrefix(token, "\"") && strings.HasSuffix(token, "\"")) || (strings.HasPrefix(token, "'") && strings.HasSuffix(token, "'")) {
-				token = token[1 : len(token)-1]
-			}
-			if len(token) > 0 {
-				tokens = append(tokens, token)
-			}
-			tokenStart = i
-		}
+// categorizeOption breaks a single option token into one or more *OptionInput, returning nil if the
+// token is not an option
+func categorizeOption(token string) []any {
+	matches := optionParser.FindAllStringSubmatch(token, -1)
+	if matches == nil {
+		return nil
+	}
 
-		if cmd[i] == '"' || cmd[i] == '\'' {
-			if openQuote {
-				if quoteChar == cmd[i] {
-					openQuote = false
-				}
-			} else {
-				quoteChar = cmd[i]
-				openQuote = true
-			}
+	output := []any{}
+	inner := matches[0]
+	// Single character option
+	if inner[2] != "" {
+		output = append(output, &OptionInput{
+			Name: inner[2],
+		})
+	}
+
+	// Single character option with equals
+	if inner[4] != "" {
+		output = append(output, &OptionInput{
+			Name:  inner[4],
+			Value: inner[5],
+		})
+	}
+
+	// Grouped single options
+	if inner[7] != "" {
+		for _, c := range inner[7] {
+			output = append(output, &OptionInput{
+				Name: string(c),
+			})
 		}
 	}
-	token := strings.Trim(cmd[tokenStart:], " ")
-	if (strings.HasPrefix(token, "\"") && strings.HasSuffix(token, "\"")) || (strings.HasPrefix(token, "'") && strings.HasSuffix(token, "'")) {
-		token = token[1 : len(token)-1]
+
+	// Long form option
+	if inner[9] != "" {
+		output = append(output, &OptionInput{
+			Name: inner[9],
+		})
 	}
-	if len(token) > 0 {
-		tokens = append(tokens, token)
+
+	// Long form option with equals
+	if inner[11] != "" {
+		output = append(output, &OptionInput{
+			Name:  inner[11],
+			Value: inner[12],
+		})
 	}
 
-	return tokens, openQuote
+	return output
+}
+
+// acceptsDashValue returns true when a dash prefixed token should be read as a value of the supplied type
+func acceptsDashValue(value string, argType ArgType) bool {
+	rt, ok := lookupType(argType)
+	if !ok || !rt.options.AcceptsDashValue {
+		return false
+	}
+	_, err := rt.parser(value)
+	return err == nil
 }
 
 // convert converts the provided input value to the specified argument type
 func convert(value string, argType ArgType) (any, error) {
-	if argType == "" || argType == String {
-		return value, nil
-	}
-	switch argType {
-	case Int:
-		val, err := strconv.Atoi(value)
-		if err != nil {
-			return nil, fmt.Errorf("expected an integer value")
-		}
-		return val, nil
-	case Float:
-		val, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return nil, fmt.Errorf("expected a floating point value")
-		}
-		return val, nil
-	case Bool:
-		val := strings.ToLower(value)
-		if val == "true" {
-			return true, nil
-		}
-		if val == "false" {
-			return false, nil
-		}
-		return nil, fmt.Errorf("expected a boolean value")
-	default:
+	rt, ok := lookupType(argType)
+	if !ok {
 		return nil, fmt.Errorf("unexpected data type")
 	}
+	return rt.parser(value)
 }
@@ -1,6 +1,9 @@
 package artillery
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestTokenizer(t *testing.T) {
 	cmd := "  this  is  \"a test of \" some tokens  "
@@ -47,7 +50,7 @@ func TestParser(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	opts, args, err := group(all)
+	opts, args, err := group(all, false)
 	if err != nil {
 		t.Error(err)
 		return
@@ -96,7 +99,7 @@ func TestParser2(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	opts, args, err := group(all)
+	opts, args, err := group(all, false)
 	if err != nil {
 		t.Error(err)
 		return
@@ -149,7 +152,7 @@ func TestParser3(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	opts, args, err := group(all)
+	opts, args, err := group(all, false)
 	if err != nil {
 		t.Error(err)
 		return
@@ -202,7 +205,7 @@ func TestParser4(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	opts, args, err := group(all)
+	opts, args, err := group(all, false)
 	if err != nil {
 		t.Error(err)
 		return
@@ -242,7 +245,7 @@ func TestParserQuotes(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	opts, args, err := group(all)
+	opts, args, err := group(all, false)
 	if err != nil {
 		t.Error(err)
 		return
@@ -274,3 +277,126 @@ func TestParserQuotes(t *testing.T) {
 		}
 	}
 }
+
+func TestParserEndOfOptions(t *testing.T) {
+	cmd := "-a -- -b --hello=world"
+	all, err := parse(cmd)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	opts, args, err := group(all, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(opts) != 1 || opts[0].Name != "a" {
+		t.Errorf("Expected only option a, got %v", opts)
+	}
+
+	expArgs := []string{"-b", "--hello=world"}
+	if len(args) != len(expArgs) {
+		t.Errorf("Expected %d arguments, got %d", len(expArgs), len(args))
+		return
+	}
+	for idx, arg := range args {
+		if expArgs[idx] != arg {
+			t.Errorf("Arg %d did not match:  Expected %s, got %s", idx, expArgs[idx], arg)
+		}
+	}
+}
+
+func TestParserDashInput(t *testing.T) {
+	all, err := parse("-5 -12 -a -3.5")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(all) != 4 {
+		t.Errorf("Expected 4 tokens, got %d", len(all))
+		return
+	}
+	if all[0] != dashInput("-5") || all[1] != dashInput("-12") {
+		t.Errorf("Expected dash inputs, got %v", all[:2])
+	}
+	if _, ok := all[2].(*OptionInput); !ok {
+		t.Errorf("Expected option input, got %T", all[2])
+	}
+	if all[3] != "-3.5" {
+		t.Errorf("Expected a plain argument, got %v", all[3])
+	}
+}
+
+func TestParserInterleaved(t *testing.T) {
+	all, err := parse("web --force api -n=3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, _, err = group(all, false)
+	if err == nil {
+		t.Errorf("Expected strict grouping to reject options after positional arguments")
+	}
+
+	opts, args, err := group(all, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(opts) != 2 {
+		t.Errorf("Expected 2 options, got %d", len(opts))
+	}
+	if len(args) != 2 || args[0] != "web" || args[1] != "api" {
+		t.Errorf("Expected arguments web and api, got %v", args)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	midnight := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value    string
+		expected time.Time
+		isError  bool
+	}{
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024-01-02T03:04:05+02:00", time.Date(2024, 1, 2, 1, 4, 5, 0, time.UTC), false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-02 03:04", time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC), false},
+		{"now", now, false},
+		{"today", midnight, false},
+		{"Yesterday", midnight.AddDate(0, 0, -1), false},
+		{"tomorrow", midnight.AddDate(0, 0, 1), false},
+		{"-2h", now.Add(-2 * time.Hour), false},
+		{"+90m", now.Add(90 * time.Minute), false},
+		{"last week", time.Time{}, true},
+		{"2024-13-01", time.Time{}, true},
+	}
+
+	for _, c := range cases {
+		result, err := parseTime(c.value, now)
+		if (err != nil) != c.isError {
+			t.Errorf("Value %q expected error %v, got %v", c.value, c.isError, err)
+			continue
+		}
+		if !result.Equal(c.expected) {
+			t.Errorf("Value %q expected %v, got %v", c.value, c.expected, result)
+		}
+	}
+}
+
+func TestConvertDuration(t *testing.T) {
+	val, err := convert("1h30m", Duration)
+	if err != nil || val != 90*time.Minute {
+		t.Errorf("Expected 1h30m, got %v %v", val, err)
+	}
+	if _, err = convert("90", Duration); err == nil {
+		t.Error("Expected an error for a duration without units")
+	}
+	if !acceptsDashValue("-5m", Duration) || acceptsDashValue("-v", Duration) {
+		t.Error("Expected only negative durations to be accepted as dash values")
+	}
+}
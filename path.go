@@ -0,0 +1,133 @@
+package artillery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathCheck describes the checks applied to Path, File and Dir values when a command executes
+type PathCheck int
+
+const (
+	PathExists   PathCheck = 1 << iota // The path must exist
+	PathReadable                       // The path must exist and be readable
+	PathAbsent                         // Nothing may exist at the path
+)
+
+// validatePathCheck ensures that the checks are meaningful for the type
+func validatePathCheck(check PathCheck, argType ArgType) error {
+	if check == 0 {
+		return nil
+	}
+	if !isPathType(argType) {
+		return fmt.Errorf("PathCheck may only be used with the path, file and dir types")
+	}
+	if check&PathAbsent != 0 && check&(PathExists|PathReadable) != 0 {
+		return fmt.Errorf("PathAbsent cannot be combined with PathExists or PathReadable")
+	}
+	return nil
+}
+
+// isPathType returns true for the filesystem path types
+func isPathType(argType ArgType) bool {
+	return argType == Path || argType == File || argType == Dir
+}
+
+// checkPath applies the checks to the path.  Regardless of the checks, an existing path must be a directory
+// for the Dir type, and must not be one for the File type.
+func checkPath(path string, argType ArgType, check PathCheck) error {
+	if !isPathType(argType) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if check&(PathExists|PathReadable) != 0 {
+			return fmt.Errorf("%s does not exist", path)
+		}
+		return nil
+	}
+
+	if check&PathAbsent != 0 {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if argType == File && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if argType == Dir && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if check&PathReadable != 0 {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%s is not readable", path)
+		}
+		f.Close()
+	}
+
+	return nil
+}
+
+// expandHome replaces a leading ~ with the home directory of the current user
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
+
+// completeFiles lists the files and directories which begin with the prefix, directories are suffixed with
+// a / so that completion can continue into them.  Hidden entries are only listed once the prefix names a
+// hidden entry.
+func completeFiles(prefix string, dirsOnly bool) []string {
+	dirPart := ""
+	basePart := prefix
+	if idx := strings.LastIndex(prefix, "/"); idx != -1 {
+		dirPart = prefix[:idx+1]
+		basePart = prefix[idx+1:]
+	} else if prefix == "~" {
+		return []string{"~/"}
+	}
+
+	lookup := expandHome(dirPart)
+	if lookup == "" {
+		lookup = "."
+	}
+	entries, err := os.ReadDir(lookup)
+	if err != nil {
+		return []string{}
+	}
+
+	results := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, basePart) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(basePart, ".")) {
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(lookup, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if isDir {
+			results = append(results, dirPart+name+"/")
+		} else if !dirsOnly {
+			results = append(results, dirPart+name)
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
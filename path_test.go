@@ -0,0 +1,182 @@
+package artillery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func makePathTree(t *testing.T) string {
+	dir := t.TempDir()
+	for _, name := range []string{"alpha.txt", "my file.txt", ".hidden", "beta/inner.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCompleteFiles(t *testing.T) {
+	dir := makePathTree(t)
+	cases := []struct {
+		prefix   string
+		dirsOnly bool
+		expected []string
+	}{
+		{dir + "/", false, []string{dir + "/alpha.txt", dir + "/beta/", dir + "/my file.txt"}},
+		{dir + "/", true, []string{dir + "/beta/"}},
+		{dir + "/.", false, []string{dir + "/.hidden"}},
+		{dir + "/be", false, []string{dir + "/beta/"}},
+		{dir + "/beta/", false, []string{dir + "/beta/inner.txt"}},
+		{dir + "/missing/", false, []string{}},
+	}
+
+	for _, c := range cases {
+		if result := completeFiles(c.prefix, c.dirsOnly); !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("Prefix %q expected %q, got %q", c.prefix, c.expected, result)
+		}
+	}
+}
+
+func TestCheckPath(t *testing.T) {
+	dir := makePathTree(t)
+	file := filepath.Join(dir, "alpha.txt")
+	missing := filepath.Join(dir, "missing.txt")
+	cases := []struct {
+		path    string
+		argType ArgType
+		check   PathCheck
+		isError bool
+	}{
+		{file, Path, PathExists, false},
+		{file, File, PathReadable, false},
+		{file, Dir, 0, true},
+		{dir, File, 0, true},
+		{dir, Dir, PathExists, false},
+		{missing, File, 0, false},
+		{missing, File, PathExists, true},
+		{missing, Path, PathAbsent, false},
+		{file, Path, PathAbsent, true},
+		{missing, String, PathExists, false},
+	}
+
+	for _, c := range cases {
+		if err := checkPath(c.path, c.argType, c.check); (err != nil) != c.isError {
+			t.Errorf("Path %q as %s with check %d expected error %v, got %v", c.path, c.argType, c.check, c.isError, err)
+		}
+	}
+
+	if err := validatePathCheck(PathExists|PathAbsent, Path); err == nil {
+		t.Error("Expected an error combining PathExists and PathAbsent")
+	}
+	if err := validatePathCheck(PathExists, String); err == nil {
+		t.Error("Expected an error using PathCheck with a string")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("No home directory")
+	}
+	for value, expected := range map[string]string{
+		"~":         home,
+		"~/x":       home + "/x",
+		"~user/x":   "~user/x",
+		"/tmp/~/x":  "/tmp/~/x",
+		"plain.txt": "plain.txt",
+	} {
+		if result := expandHome(value); result != expected {
+			t.Errorf("Value %q expected %q, got %q", value, expected, result)
+		}
+	}
+}
+
+func TestProcessorPathCompletion(t *testing.T) {
+	dir := makePathTree(t)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Chdir(cwd)
+
+	var opened string
+	processor := NewProcessor()
+	processor.AddCommands(&Command{
+		Name:        "open",
+		Description: "open a file",
+		Arguments: []*Argument{
+			{
+				Name:        "file",
+				Description: "file to open",
+				Type:        File,
+				PathCheck:   PathExists,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			opened = ns["file"].(string)
+			return nil
+		},
+	}, &Command{
+		Name:        "visit",
+		Description: "visit a city",
+		Arguments: []*Argument{
+			{
+				Name:        "city",
+				Description: "city to visit",
+				MemberOf:    []string{"new york"},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	})
+
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"open a", []string{"alpha.txt"}},
+		{"open m", []string{`my\ file.txt`}},
+		{`open my\ f`, []string{"file.txt"}},
+		{"open 'my", []string{"'my file.txt"}},
+		{`open "my f`, []string{"file.txt"}},
+		{"open b", []string{"beta/"}},
+		{"open beta/", []string{"beta/inner.txt"}},
+		{"emit > al", []string{"alpha.txt"}},
+		{"visit n", []string{"new york"}},
+		{"visit 'n", []string{}},
+		{"'op", []string{}},
+	}
+
+	for _, c := range cases {
+		sug := processor.OnComplete(c.input, "", c.input)
+		names := []string{}
+		for _, s := range sug {
+			names = append(names, s.Name)
+		}
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("Input %q expected %q, got %q", c.input, c.expected, names)
+		}
+	}
+
+	err = processor.onExecute(nil, `open my\ file.txt`, true)
+	if err != nil || opened != "my file.txt" {
+		t.Errorf("Expected to open \"my file.txt\", got %q %v", opened, err)
+	}
+	err = processor.onExecute(nil, "open missing.txt", true)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package artillery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessorPipeline(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		isError  bool
+	}{
+		{"emit cat dog catfish | count", "3\n", false},
+		{"emit cat dog catfish | filter cat", "cat\ncatfish\n", false},
+		{"emit cat dog catfish | filter cat | count", "2\n", false},
+		{"emit 'a | b' | count", "1\n", false},
+		{"emit a b | count && emit done", "2\ndone\n", false},
+		{"fail | count", "0\n", false},
+		{"emit a b | fail", "", true},
+		{"count", "0\n", false},
+	}
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommands(
+		&Command{
+			Name:        "emit",
+			Description: "writes each argument on its own line",
+			Arguments: []*Argument{
+				{
+					Name:        "lines",
+					Description: "lines to write",
+					IsArray:     true,
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				for _, line := range ns["lines"].([]string) {
+					fmt.Fprintln(processor.Stdout(), line)
+				}
+				return nil
+			},
+		},
+		&Command{
+			Name:        "filter",
+			Description: "passes through lines containing the substring",
+			Arguments: []*Argument{
+				{
+					Name:        "substring",
+					Description: "substring to match",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				scanner := bufio.NewScanner(processor.Stdin())
+				for scanner.Scan() {
+					if strings.Contains(scanner.Text(), ns["substring"].(string)) {
+						fmt.Fprintln(processor.Stdout(), scanner.Text())
+					}
+				}
+				return scanner.Err()
+			},
+		},
+		&Command{
+			Name:        "count",
+			Description: "counts lines of input",
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				count := 0
+				scanner := bufio.NewScanner(processor.Stdin())
+				for scanner.Scan() {
+					count++
+				}
+				fmt.Fprintln(processor.Stdout(), count)
+				return scanner.Err()
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				return fmt.Errorf("failed")
+			},
+		},
+	)
+
+	for _, c := range cases {
+		stdout.Reset()
+		err := processor.onExecute(nil, c.input, true)
+		if (err != nil) != c.isError {
+			t.Errorf("Input %q expected error %v, got %v", c.input, c.isError, err)
+		}
+		if stdout.String() != c.expected {
+			t.Errorf("Input %q expected output %q, got %q", c.input, c.expected, stdout.String())
+		}
+	}
+}
+
+func TestProcessorPipelineSharesState(t *testing.T) {
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "fail",
+		Description: "always fails",
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return fmt.Errorf("failed")
+		},
+	})
+	stage := processor.withStreams(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	stage.onExecute(nil, "fail", true)
+	if processor.lastStatus != 1 {
+		t.Errorf("Expected the status of a pipeline stage to be shared, got %d", processor.lastStatus)
+	}
+}
+
+func TestProcessorPipelineSource(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "emit.art")
+	os.WriteFile(script, []byte("emit a\n"), 0644)
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommand(&Command{
+		Name:        "emit",
+		Description: "writes each argument on its own line",
+		Arguments: []*Argument{
+			{
+				Name:        "lines",
+				Description: "lines to write",
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			for _, line := range ns["lines"].([]string) {
+				fmt.Fprintln(processor.Stdout(), line)
+			}
+			return nil
+		},
+	})
+	err := processor.onExecute(nil, "source "+quoteWord(script)+" | source "+quoteWord(script), true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if stdout.String() != "a\n" {
+		t.Errorf("Expected output \"a\\n\", got %q", stdout.String())
+	}
+}
+
+func TestProcessorPipelineSyntax(t *testing.T) {
+	processor := NewProcessor()
+	for _, input := range []string{"| count", "emit a |", "emit a | | count"} {
+		if err := processor.onExecute(nil, input, true); err == nil {
+			t.Errorf("Input %q expected a syntax error", input)
+		}
+	}
+}
+
+func TestProcessorPipelineExternal(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr is not available")
+	}
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommands(
+		&Command{
+			Name:        "emit",
+			Description: "writes each argument on its own line",
+			Arguments: []*Argument{
+				{
+					Name:        "lines",
+					Description: "lines to write",
+					IsArray:     true,
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				for _, line := range ns["lines"].([]string) {
+					fmt.Fprintln(processor.Stdout(), line)
+				}
+				return nil
+			},
+		},
+		&Command{
+			Name:        "filter",
+			Description: "passes through lines containing the substring",
+			Arguments: []*Argument{
+				{
+					Name:        "substring",
+					Description: "substring to match",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				scanner := bufio.NewScanner(processor.Stdin())
+				for scanner.Scan() {
+					if strings.Contains(scanner.Text(), ns["substring"].(string)) {
+						fmt.Fprintln(processor.Stdout(), scanner.Text())
+					}
+				}
+				return scanner.Err()
+			},
+		},
+	)
+	err := processor.onExecute(nil, "emit cat dog | !tr a-z A-Z | filter CAT", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if stdout.String() != "CAT\n" {
+		t.Errorf("Expected output \"CAT\\n\", got %q", stdout.String())
+	}
+}
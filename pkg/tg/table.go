@@ -2,6 +2,8 @@ package tg
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
@@ -45,18 +47,23 @@ func (t *Table) Append(values ...string) {
 
 // Render prints the table to the stdout
 func (t *Table) Render() {
+	t.RenderTo(os.Stdout)
+}
+
+// RenderTo prints the table to the supplied writer
+func (t *Table) RenderTo(w io.Writer) {
 	values := make([]string, len(t.columns))
 	for idx, col := range t.columns {
 		values[idx] = fmt.Sprintf("%s%s", strings.ToUpper(col), strings.Repeat(" ", t.columnWidths[idx]-len(col)))
 	}
 	if !t.HideHeading {
-		Println(Bold, Underline, White, strings.Join(values, strings.Repeat(" ", gutter)), Reset)
+		Fprintln(w, Bold, Underline, White, strings.Join(values, strings.Repeat(" ", gutter)), Reset)
 	}
 	for _, row := range t.rows {
 		for idx := range t.columns {
 			col := row[idx]
 			values[idx] = fmt.Sprintf("%s%s", col, strings.Repeat(" ", t.columnWidths[idx]-len(col)))
 		}
-		Println(White, strings.Join(values, strings.Repeat(" ", gutter)), Reset)
+		Fprintln(w, White, strings.Join(values, strings.Repeat(" ", gutter)), Reset)
 	}
 }
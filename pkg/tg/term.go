@@ -2,18 +2,30 @@ package tg
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
 // Print displays output to the stdout
 func Print(args ...any) {
-	fmt.Print(Sprint(args...))
+	Fprint(os.Stdout, args...)
 }
 
 // Println displays output to the stdout followed by a newline
 func Println(args ...any) {
+	Fprintln(os.Stdout, args...)
+}
+
+// Fprint displays output to the supplied writer
+func Fprint(w io.Writer, args ...any) {
+	fmt.Fprint(w, Sprint(args...))
+}
+
+// Fprintln displays output to the supplied writer followed by a newline
+func Fprintln(w io.Writer, args ...any) {
 	newArgs := append(args, "\n")
-	fmt.Print(Sprint(newArgs...))
+	fmt.Fprint(w, Sprint(newArgs...))
 }
 
 // Sprint prints output to a string
@@ -1,22 +1,59 @@
 package artillery
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hashibuto/artillery/pkg/tg"
 	ns "github.com/hashibuto/nilshell"
+	"golang.org/x/term"
 )
 
 type Processor struct {
-	DefaultHeading  string
-	DisableBuiltins bool
-	nilShell        *ns.NilShell
-	commandLookup   map[string]*Command
+	DefaultHeading     string
+	DisableBuiltins    bool
+	DefaultParseMode   ParseMode // Parse mode applied to commands which don't declare their own
+	ContinuationPrompt string    // Prompt displayed while reading the remaining lines of an incomplete command
+
+	*processorState
+
+	// Streams for the current invocation, see Stdin, Stdout and Stderr
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	sources []string // Scripts being run by the current invocation, outermost first
+}
+
+// processorState is shared by the processor and each invocation created from it by withStreams
+type processorState struct {
+	nilShell      *ns.NilShell
+	commandLookup map[string]*Command
+	variables     *variableStore
+	aliases       *variableStore
+	activeAliases map[string]bool // Aliases already expanded by the completion in progress
+	config        *configFile     // Option values loaded by LoadConfig
+
+	// Guards the run state below, which is updated by each stage of a pipeline
+	lock        sync.Mutex
+	lastStatus  int
+	exited      bool // Set by the exit command, stopping the outermost run and everything it's running
+	runs        int  // Depth of the runs in progress, see beginRun
+	interactive bool // Whether the outermost run is a command of the interactive shell
+
+	// Lines of an incomplete interactive command, and the prompt and history to restore once it is complete
+	continuation    []string
+	prompt          string
+	history         *ns.History
+	historyTail     string // Most recent command of the history once the previous command was handled
+	historyAppended bool   // Whether the shell recorded the first line of the incomplete command in its history
 
 	beforeAndCursor string
 	afterCursor     string
@@ -25,8 +62,13 @@ type Processor struct {
 
 func NewProcessor() *Processor {
 	proc := &Processor{
-		DefaultHeading: "commands",
-		commandLookup:  map[string]*Command{},
+		DefaultHeading:     "commands",
+		ContinuationPrompt: "> ",
+		processorState: &processorState{
+			commandLookup: map[string]*Command{},
+			variables:     newVariableStore(),
+			aliases:       newVariableStore(),
+		},
 	}
 	proc.nilShell = ns.NewShell("» ", proc.OnComplete, proc.OnExecute)
 	err := proc.AddCommand(makeHelpCommand())
@@ -45,6 +87,26 @@ func NewProcessor() *Processor {
 	if err != nil {
 		panic(fmt.Sprintf("Problem with the exit command\n%v", err))
 	}
+	err = proc.AddCommand(makeLetCommand())
+	if err != nil {
+		panic(fmt.Sprintf("Problem with the let command\n%v", err))
+	}
+	err = proc.AddCommand(makeAliasCommand())
+	if err != nil {
+		panic(fmt.Sprintf("Problem with the alias command\n%v", err))
+	}
+	err = proc.AddCommand(makeUnaliasCommand())
+	if err != nil {
+		panic(fmt.Sprintf("Problem with the unalias command\n%v", err))
+	}
+	err = proc.AddCommand(makeSourceCommand())
+	if err != nil {
+		panic(fmt.Sprintf("Problem with the source command\n%v", err))
+	}
+	err = proc.AddCommand(makeConfigCommand())
+	if err != nil {
+		panic(fmt.Sprintf("Problem with the config command\n%v", err))
+	}
 	return proc
 }
 
@@ -62,6 +124,48 @@ func (p *Processor) Shell() *ns.NilShell {
 	return p.nilShell
 }
 
+// Stdin returns the input stream of the current invocation.  Commands should read from this rather than
+// os.Stdin, since their input may be supplied by a previous command in a pipeline.
+func (p *Processor) Stdin() io.Reader {
+	if p == nil || p.stdin == nil {
+		return os.Stdin
+	}
+	return p.stdin
+}
+
+// Stdout returns the output stream of the current invocation.  Commands should write to this rather than
+// os.Stdout, since their output may be consumed by the next command in a pipeline.
+func (p *Processor) Stdout() io.Writer {
+	if p == nil || p.stdout == nil {
+		return os.Stdout
+	}
+	return p.stdout
+}
+
+// Stderr returns the error stream of the current invocation
+func (p *Processor) Stderr() io.Writer {
+	if p == nil || p.stderr == nil {
+		return os.Stderr
+	}
+	return p.stderr
+}
+
+// withStreams returns an invocation of the processor which shares its settings and state, but uses the
+// supplied streams and its own copy of the script stack
+func (p *Processor) withStreams(stdin io.Reader, stdout io.Writer, stderr io.Writer) *Processor {
+	return &Processor{
+		DefaultHeading:     p.DefaultHeading,
+		DisableBuiltins:    p.DisableBuiltins,
+		DefaultParseMode:   p.DefaultParseMode,
+		ContinuationPrompt: p.ContinuationPrompt,
+		processorState:     p.processorState,
+		stdin:              stdin,
+		stdout:             stdout,
+		stderr:             stderr,
+		sources:            append([]string{}, p.sources...),
+	}
+}
+
 // AddCommands adds several commands to the processor at once
 func (p *Processor) AddCommands(cmds ...*Command) error {
 	for _, c := range cmds {
@@ -90,20 +194,48 @@ func (p *Processor) AddCommand(cmd *Command) error {
 	return nil
 }
 
+// lookupCommandPath returns the command named by a command name followed by subcommand names
+func (p *Processor) lookupCommandPath(names []string) (*Command, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no command specified")
+	}
+
+	var curCommand *Command
+	var ok bool
+	curLookup := p.commandLookup
+	for _, cmdName := range names {
+		curCommand, ok = curLookup[cmdName]
+		if !ok {
+			return nil, fmt.Errorf("unknown command or subcommand \"%s\"", cmdName)
+		}
+		curLookup = curCommand.subCommandLookup
+	}
+	return curCommand, nil
+}
+
+// Run reads and executes commands until the input is exhausted or the user exits.  When stdin is a terminal
+// this is the interactive shell, otherwise newline delimited commands are read from stdin and executed
+// without a prompt, continuing past failures.  An error is returned if any of those commands failed.
+func (p *Processor) Run() error {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		p.historyTail = historyTail(p.nilShell.History)
+		return p.nilShell.ReadUntilTerm()
+	}
+
+	return p.RunScript(os.Stdin, ScriptOptions{
+		Name:            "stdin",
+		ContinueOnError: true,
+	})
+}
+
 // Process processes the supplied cliArgs as though this were a standalone commmand.  This is useful for processing arguments directly from
-// the cli
+// the cli.  Each argument is taken literally, so operators such as ">" or ";" are passed to the command rather
+// than interpreted, leaving redirection to the calling shell.
 func (p *Processor) Process(cliArgs []string) error {
+	defer p.beginRun(false)()
 	finalArgs := []string{}
 	for _, arg := range cliArgs {
-		newArg := arg
-		if strings.Contains(arg, " ") || strings.Contains(arg, "\"") || strings.Contains(arg, "'") {
-			if !strings.Contains(arg, "\"") {
-				newArg = fmt.Sprintf("\"%s\"", arg)
-			} else {
-				newArg = fmt.Sprintf("'%s'", arg)
-			}
-		}
-		finalArgs = append(finalArgs, newArg)
+		finalArgs = append(finalArgs, quoteWord(arg))
 	}
 
 	input := strings.Join(finalArgs, " ")
@@ -111,12 +243,75 @@ func (p *Processor) Process(cliArgs []string) error {
 }
 
 func (p *Processor) OnExecute(nilShell *ns.NilShell, input string) {
+	if nilShell == nil {
+		p.onExecute(nil, input, false)
+		return
+	}
+
+	input, complete := p.continueInput(nilShell, input)
+	if !complete {
+		return
+	}
+	endRun := p.beginRun(true)
 	p.onExecute(nilShell, input, false)
+	endRun()
+	p.historyTail = historyTail(nilShell.History)
+}
+
+// beginRun scopes the exit command to a run, which is a call to Process or RunScript, or a command of the
+// interactive shell.  Exiting stops the outermost run in progress, along with everything it's running.
+// The returned function ends the run.
+func (p *Processor) beginRun(interactive bool) func() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.runs == 0 {
+		p.interactive = interactive
+	}
+	p.runs++
+	return func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.runs--
+		if p.runs == 0 {
+			p.exited = false
+			p.interactive = false
+		}
+	}
+}
+
+// exit stops the outermost run in progress, returning true when it's a command of the interactive shell
+func (p *Processor) exit() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.exited = true
+	return p.interactive
+}
+
+// hasExited returns true once the exit command has stopped the outermost run in progress
+func (p *Processor) hasExited() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.exited
+}
+
+// setStatus records the exit status of the last command run, see Variable
+func (p *Processor) setStatus(status int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.lastStatus = status
+}
+
+// status returns the exit status of the last command run
+func (p *Processor) status() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.lastStatus
 }
 
 func (p *Processor) onExecute(nilShell *ns.NilShell, input string, silent bool) error {
+	// Commands run by a script are reported by line number, leaving the usage hint to the command line
 	var helpStr string
-	if nilShell == nil {
+	if nilShell == nil && len(p.sources) == 0 {
 		bin := os.Args[0]
 		_, fname := filepath.Split(bin)
 		helpStr = fmt.Sprintf("  Type \"%s help\" for usage.", fname)
@@ -126,38 +321,166 @@ func (p *Processor) onExecute(nilShell *ns.NilShell, input string, silent bool)
 		return fmt.Errorf("No input supplied.%s", helpStr)
 	}
 
-	if input[0] == '!' {
-		input = input[1:]
-		tokens, openQuote := tokenize(input)
-		if openQuote {
+	lexed := lex(input)
+	if lexed.openQuote {
+		if !silent {
+			tg.Fprintln(p.Stderr(), tg.Red, "Unterminated quotation", tg.Reset)
+		}
+		return fmt.Errorf("Unterminated quotation")
+	}
+
+	chain, err := splitChain(p.expandAliases(lexed.words, map[string]bool{}))
+	if err != nil {
+		if !silent {
+			tg.Fprintln(p.Stderr(), tg.Red, err, tg.Reset)
+		}
+		return err
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("No input supplied.%s", helpStr)
+	}
+
+	var lastErr error
+	for _, link := range chain {
+		if p.hasExited() {
+			break
+		}
+		if !link.shouldRun(lastErr) {
+			continue
+		}
+		lastErr = p.executePipeline(nilShell, link.stages, silent, helpStr)
+		p.setStatus(exitStatus(lastErr))
+	}
+
+	return lastErr
+}
+
+// stage is a single command within a pipeline, ready for execution
+type stage struct {
+	words     []string
+	external  bool
+	redirects []*redirect
+}
+
+// prepareStage extracts redirections from the command and expands its variables
+func (p *Processor) prepareStage(cmdWords []*word) (*stage, error) {
+	cmdWords, redirects, err := extractRedirects(cmdWords)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &stage{
+		redirects: redirects,
+	}
+	cmdWords, st.external = stripExternal(cmdWords)
+	st.words = p.expandWords(cmdWords)
+	for _, r := range redirects {
+		r.path = p.expandWords([]*word{r.target})[0]
+	}
+
+	return st, nil
+}
+
+// executePipeline executes each stage of a pipeline concurrently, connecting the output of each stage to
+// the input of the next.  The result of the pipeline is the result of its final stage.
+func (p *Processor) executePipeline(nilShell *ns.NilShell, stageWords [][]*word, silent bool, helpStr string) error {
+	// Expand everything up front, since a stage may alter variables while the others are running
+	stages := make([]*stage, len(stageWords))
+	for idx, cmdWords := range stageWords {
+		st, err := p.prepareStage(cmdWords)
+		if err != nil {
 			if !silent {
-				tg.Println(tg.Red, "Unterminated quotation", tg.Reset)
+				tg.Fprintln(p.Stderr(), tg.Red, err, tg.Reset)
 			}
-			return fmt.Errorf("Unterminated quotation")
+			return err
 		}
-		if len(tokens) == 0 {
-			return fmt.Errorf("No input supplied.%s", helpStr)
+		stages[idx] = st
+	}
+
+	if len(stages) == 1 {
+		return p.executeStage(nilShell, stages[0], silent, helpStr)
+	}
+
+	errs := make([]error, len(stages))
+	wg := sync.WaitGroup{}
+	var stdin io.Reader = p.Stdin()
+	var pipeIn *io.PipeReader
+	for idx, st := range stages {
+		var stdout io.Writer = p.Stdout()
+		var pipeOut *io.PipeWriter
+		var nextIn *io.PipeReader
+		if idx < len(stages)-1 {
+			nextIn, pipeOut = io.Pipe()
+			stdout = pipeOut
 		}
-		args := []string{}
-		if len(tokens) > 1 {
-			args = append(args, tokens[1:]...)
+
+		stageProc := p.withStreams(stdin, stdout, p.Stderr())
+		wg.Add(1)
+		go func(idx int, st *stage, pipeIn *io.PipeReader, pipeOut *io.PipeWriter) {
+			defer wg.Done()
+			errs[idx] = stageProc.executeStage(nilShell, st, silent, helpStr)
+
+			// Signal the end of input to the next stage, and stop the previous stage from blocking on a
+			// reader which has gone away
+			if pipeOut != nil {
+				pipeOut.Close()
+			}
+			if pipeIn != nil {
+				pipeIn.Close()
+			}
+		}(idx, st, pipeIn, pipeOut)
+
+		stdin = nextIn
+		pipeIn = nextIn
+	}
+	wg.Wait()
+
+	return errs[len(errs)-1]
+}
+
+// executeStage executes a single stage of a pipeline, with any redirections applied
+func (p *Processor) executeStage(nilShell *ns.NilShell, st *stage, silent bool, helpStr string) error {
+	proc := p
+	if len(st.redirects) > 0 {
+		stdout := p.Stdout()
+		stderr := p.Stderr()
+		for _, r := range st.redirects {
+			f, err := r.open()
+			if err != nil {
+				if !silent {
+					tg.Fprintln(stderr, tg.Red, err, tg.Reset)
+				}
+				return err
+			}
+			defer f.Close()
+
+			if r.isStderr() {
+				stderr = f
+			} else {
+				stdout = f
+			}
 		}
-		cmd := exec.Command(tokens[0], args...)
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		return cmd.Run()
+		proc = p.withStreams(p.Stdin(), stdout, stderr)
 	}
 
-	// Parse input
-	tokens, err := parse(input)
-	if err != nil {
-		if !silent {
-			tg.Println(tg.Red, err, tg.Reset)
+	return proc.executeCommand(nilShell, st.words, st.external, silent, helpStr)
+}
+
+// executeCommand executes a single, expanded command.  When external is true, the command is executed by
+// the operating system.
+func (p *Processor) executeCommand(nilShell *ns.NilShell, words []string, external bool, silent bool, helpStr string) error {
+	if external {
+		if len(words) == 0 {
+			return fmt.Errorf("No input supplied.%s", helpStr)
 		}
-		return err
+		cmd := exec.Command(words[0], words[1:]...)
+		cmd.Stdin = p.Stdin()
+		cmd.Stdout = p.Stdout()
+		cmd.Stderr = p.Stderr()
+		return cmd.Run()
 	}
 
+	tokens := categorizeWords(words)
 	if len(tokens) == 0 {
 		return fmt.Errorf("No input supplied.%s", helpStr)
 	}
@@ -165,7 +488,7 @@ func (p *Processor) onExecute(nilShell *ns.NilShell, input string, silent bool)
 	cmdStr, tokens, err := extractCommand(tokens)
 	if err != nil {
 		if !silent {
-			tg.Println(tg.Red, err, helpStr, tg.Reset)
+			tg.Fprintln(p.Stderr(), tg.Red, err, helpStr, tg.Reset)
 		}
 		return err
 	}
@@ -173,41 +496,119 @@ func (p *Processor) onExecute(nilShell *ns.NilShell, input string, silent bool)
 	cmd, ok := p.commandLookup[cmdStr]
 	if !ok {
 		if !silent {
-			tg.Println(tg.Red, "Command \"", cmdStr, "\" not found.", tg.Reset, helpStr)
+			tg.Fprintln(p.Stderr(), tg.Red, "Command \"", cmdStr, "\" not found.", tg.Reset, helpStr)
 		}
 		return fmt.Errorf("Command \"%s\" not found.%s", cmdStr, helpStr)
 	}
 	err = cmd.Execute(tokens, p, nilShell != nil)
 	if err != nil {
 		if !silent {
-			tg.Println(tg.Red, err, helpStr, tg.Reset)
+			if line, ok := highlightToken(words, err); ok && nilShell != nil {
+				fmt.Fprintln(p.Stderr(), line)
+			}
+			tg.Fprintln(p.Stderr(), tg.Red, err, helpStr, tg.Reset)
 		}
 		return err
 	}
 	return nil
 }
 
+// exitStatus converts the result of a command into a numeric status, as reported by $?
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
 func (p *Processor) OnComplete(beforeAndCursor string, afterCursor string, full string) []*ns.AutoComplete {
-	p.beforeAndCursor = beforeAndCursor
-	p.afterCursor = afterCursor
-	p.full = full
+	lexed := lex(beforeAndCursor)
+	sug, paths := p.onComplete(beforeAndCursor, afterCursor, full, map[string]bool{})
+	if !paths {
+		// Only paths are completed within quotes, since other suggestions never need quoting
+		if lexed.openQuote {
+			return []*ns.AutoComplete{}
+		}
+		return sug
+	}
+	return escapeCompletions(lexed, beforeAndCursor, sug)
+}
 
+// onComplete produces suggestions for the input, where active holds the aliases which have already been
+// expanded.  It returns true when the suggestions are paths, which must be escaped for insertion.
+func (p *Processor) onComplete(beforeAndCursor string, afterCursor string, full string, active map[string]bool) ([]*ns.AutoComplete, bool) {
+	p.activeAliases = active
 	sug := []*ns.AutoComplete{}
-	tokens, openQuote := tokenize(beforeAndCursor)
-	if openQuote {
-		return []*ns.AutoComplete{}
+	lexed := lex(beforeAndCursor)
+	if lexed.inComment {
+		return []*ns.AutoComplete{}, false
 	}
 
-	var finalChar byte
-	if len(beforeAndCursor) == 0 {
-		finalChar = ' '
-	} else {
-		finalChar = beforeAndCursor[len(beforeAndCursor)-1]
+	// Completion restarts following each control operator, so only consider the final command
+	words := currentCommand(lexed.words)
+	if n := len(words); (n > 0 && !lexed.inWord && isRedirect(words[n-1].operator)) || (n > 1 && lexed.inWord && isRedirect(words[n-2].operator)) {
+		// Completing the target of a redirection
+		prefix := ""
+		if lexed.inWord {
+			prefix = words[n-1].String()
+		}
+		for _, name := range completeFiles(prefix, false) {
+			sug = append(sug, &ns.AutoComplete{
+				Name: name,
+			})
+		}
+		return sug, true
+	}
+	words, _, err := extractRedirects(words)
+	if err != nil {
+		return sug, false
+	}
+	words, external := stripExternal(words)
+	if external {
+		return sug, false
+	}
+
+	if len(words) > 1 || (len(words) == 1 && !lexed.inWord) {
+		if name, expansion, ok := p.aliasWord(words[0]); ok && !active[name] {
+			// Complete against the expanded command, so that an alias accepts the same input as its command
+			active[name] = true
+			end := words[0].end
+			return p.onComplete(
+				expansion+string([]rune(beforeAndCursor)[end:]),
+				afterCursor,
+				expansion+string([]rune(full)[end:]),
+				active,
+			)
+		}
+	}
+
+	// Commands which complete recursively (such as help) expect to see only their own input
+	start := len([]rune(beforeAndCursor))
+	if len(words) > 0 {
+		start = words[0].start
 	}
-	if finalChar == ' ' {
+	p.beforeAndCursor = string([]rune(beforeAndCursor)[start:])
+	p.afterCursor = afterCursor
+	p.full = string([]rune(full)[start:])
+
+	tokens := make([]string, len(words))
+	for idx, w := range words {
+		tokens[idx] = w.String()
+	}
+	if !lexed.inWord {
+		// The cursor sits on a word boundary, so we're completing a brand new token
 		tokens = append(tokens, "")
 	}
 
+	if strings.HasPrefix(tokens[len(tokens)-1], "$") {
+		return p.completeVariable(tokens[len(tokens)-1]), false
+	}
+
 	curLookup := p.commandLookup
 	for idx, arg := range tokens {
 		prefix := idx == (len(tokens) - 1)
@@ -215,7 +616,7 @@ func (p *Processor) OnComplete(beforeAndCursor string, afterCursor string, full
 			cmd, ok := curLookup[arg]
 			if !ok {
 				// Will be empty
-				return sug
+				return sug, false
 			}
 
 			if len(cmd.SubCommands) == 0 {
@@ -224,15 +625,26 @@ func (p *Processor) OnComplete(beforeAndCursor string, afterCursor string, full
 				categorizedTokens := categorizeTokens(remainingTokens)
 				compressedTokens, err := cmd.CompressTokens(categorizedTokens)
 				if err != nil {
-					return sug
+					return sug, false
 				}
 
-				return cmd.OnComplete(compressedTokens, p)
+				cmdArg := cmd.completionArgument(compressedTokens)
+				paths := cmd.OnCompleteOverride == nil && cmdArg != nil && isPathType(cmdArg.Type)
+				return cmd.OnComplete(compressedTokens, p), paths
 			}
 
 			curLookup = cmd.subCommandLookup
 		} else {
+			names := map[string]bool{}
 			for name := range curLookup {
+				names[name] = true
+			}
+			if idx == 0 {
+				for _, name := range p.aliasNames() {
+					names[name] = true
+				}
+			}
+			for name := range names {
 				if strings.HasPrefix(name, arg) {
 					sug = append(sug, &ns.AutoComplete{
 						Name: name,
@@ -243,11 +655,11 @@ func (p *Processor) OnComplete(beforeAndCursor string, afterCursor string, full
 	}
 
 	if len(sug) == 1 && len(tokens) > 0 && tokens[len(tokens)-1] == sug[0].Name {
-		return []*ns.AutoComplete{}
+		return []*ns.AutoComplete{}, false
 	}
 
 	sort.Slice(sug, func(i, j int) bool {
 		return sug[i].Name < sug[j].Name
 	})
-	return sug
+	return sug, false
 }
@@ -0,0 +1,63 @@
+package artillery
+
+import (
+	"fmt"
+	"os"
+)
+
+// redirect sends one of a command's output streams to a file
+type redirect struct {
+	operator string // One of >, >>, 2> or 2>>
+	target   *word
+	path     string // Expanded from target prior to execution
+}
+
+// isRedirect returns true if the operator redirects output
+func isRedirect(operator string) bool {
+	switch operator {
+	case ">", ">>", "2>", "2>>":
+		return true
+	default:
+		return false
+	}
+}
+
+// isStderr returns true if the redirect applies to the error stream rather than the output stream
+func (r *redirect) isStderr() bool {
+	return r.operator == "2>" || r.operator == "2>>"
+}
+
+// open opens the target file for writing, truncating it unless the redirect appends
+func (r *redirect) open() (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if r.operator == ">>" || r.operator == "2>>" {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	return os.OpenFile(r.path, flags, 0644)
+}
+
+// extractRedirects removes redirections, and the file names which follow them, from the words of a command
+func extractRedirects(words []*word) ([]*word, []*redirect, error) {
+	remaining := []*word{}
+	redirects := []*redirect{}
+	for idx := 0; idx < len(words); idx++ {
+		w := words[idx]
+		if !isRedirect(w.operator) {
+			remaining = append(remaining, w)
+			continue
+		}
+
+		if idx == len(words)-1 || words[idx+1].operator != "" {
+			return nil, nil, fmt.Errorf("Syntax error, expected a file name following \"%s\"", w.operator)
+		}
+		redirects = append(redirects, &redirect{
+			operator: w.operator,
+			target:   words[idx+1],
+		})
+		idx++
+	}
+
+	return remaining, redirects, nil
+}
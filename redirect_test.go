@@ -0,0 +1,154 @@
+package artillery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestProcessorRedirect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommands(
+		&Command{
+			Name:        "emit",
+			Description: "writes each argument on its own line",
+			Arguments: []*Argument{
+				{
+					Name:        "lines",
+					Description: "lines to write",
+					IsArray:     true,
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				for _, line := range ns["lines"].([]string) {
+					fmt.Fprintln(processor.Stdout(), line)
+				}
+				return nil
+			},
+		},
+		&Command{
+			Name:        "filter",
+			Description: "passes through lines containing the substring",
+			Arguments: []*Argument{
+				{
+					Name:        "substring",
+					Description: "substring to match",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				scanner := bufio.NewScanner(processor.Stdin())
+				for scanner.Scan() {
+					if strings.Contains(scanner.Text(), ns["substring"].(string)) {
+						fmt.Fprintln(processor.Stdout(), scanner.Text())
+					}
+				}
+				return scanner.Err()
+			},
+		},
+	)
+	err := processor.onExecute(nil, "emit a b > "+path, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = processor.onExecute(nil, "emit c >>"+path, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if contents := readFile(t, path); contents != "a\nb\nc\n" {
+		t.Errorf("Expected file contents \"a\\nb\\nc\\n\", got %q", contents)
+	}
+
+	err = processor.onExecute(nil, "emit cat dog | filter dog >"+path+" && emit done", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if contents := readFile(t, path); contents != "dog\n" {
+		t.Errorf("Expected file contents \"dog\\n\", got %q", contents)
+	}
+	if stdout.String() != "done\n" {
+		t.Errorf("Expected output \"done\\n\", got %q", stdout.String())
+	}
+}
+
+func TestProcessorRedirectStderr(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "err.txt")
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	err := processor.onExecute(nil, "missing 2> "+path, false)
+	if err == nil {
+		t.Error("Expected an error")
+	}
+	if contents := readFile(t, path); !strings.Contains(contents, "not found") {
+		t.Errorf("Expected the error to be written to the file, got %q", contents)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output, got %q", stdout.String())
+	}
+}
+
+func TestProcessorRedirectProcess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my file.txt")
+
+	stdout := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), stdout, &bytes.Buffer{})
+	processor.AddCommand(&Command{
+		Name:        "emit",
+		Description: "writes each argument on its own line",
+		Arguments: []*Argument{
+			{
+				Name:        "lines",
+				Description: "lines to write",
+				IsArray:     true,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			for _, line := range ns["lines"].([]string) {
+				fmt.Fprintln(processor.Stdout(), line)
+			}
+			return nil
+		},
+	})
+	err := processor.Process([]string{"emit", "a b", ">", path, "2>>", ";"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := "a b\n>\n" + path + "\n2>>\n;\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected the operators to be passed literally %q, got %q", expected, stdout.String())
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Errorf("Expected %s not to be created", path)
+	}
+}
+
+func TestProcessorRedirectSyntax(t *testing.T) {
+	processor := NewProcessor()
+	for _, input := range []string{"emit a >", "emit a > ; emit b", "emit a 2>>"} {
+		if err := processor.onExecute(nil, input, true); err == nil {
+			t.Errorf("Input %q expected a syntax error", input)
+		}
+	}
+}
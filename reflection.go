@@ -1,12 +1,15 @@
 package artillery
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/hashibuto/mirage"
 )
 
-// Reflect attempts to reflect the data in namespace to the provided object
+// Reflect attempts to reflect the data in namespace to the provided object.  Values of the JSON type are decoded
+// into fields of other types, such as structs, as encoding/json would.
 func Reflect(namespace Namespace, obj any) error {
 	lowerToKey := map[string]string{}
 	ref := mirage.Reflect(obj, "")
@@ -22,7 +25,29 @@ func Reflect(namespace Namespace, obj any) error {
 		lKey := strings.ToLower(key)
 
 		if objKey, ok := lowerToKey[lKey]; ok {
-			err := refIo.SetValueByName(objKey, value)
+			field, err := ref.FieldByName(objKey)
+			if err != nil {
+				return err
+			}
+
+			val := reflect.ValueOf(value)
+			if !val.Type().AssignableTo(field.Type) && isJSONValue(value) {
+				// JSON objects and arrays may be decoded into structs, maps and slices
+				value, err = convertJSON(value, field.Type)
+				if err != nil {
+					return fmt.Errorf("Cannot assign JSON value of %s to field %s of type %s - %s", key, objKey, field.Type, err)
+				}
+			} else if !val.Type().AssignableTo(field.Type) {
+				if field.Type.Kind() != reflect.Pointer || !val.Type().AssignableTo(field.Type.Elem()) {
+					return fmt.Errorf("Cannot assign %s value of %s to field %s of type %s", val.Type(), key, objKey, field.Type)
+				}
+				// Optional values may be reflected into pointer fields
+				ptr := reflect.New(field.Type.Elem())
+				ptr.Elem().Set(val)
+				value = ptr.Interface()
+			}
+
+			err = refIo.SetValueByName(objKey, value)
 			if err != nil {
 				return err
 			}
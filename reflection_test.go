@@ -34,3 +34,14 @@ func TestReflect(t *testing.T) {
 		t.Errorf("Got incorrect friends")
 	}
 }
+
+func TestReflectTypeMismatch(t *testing.T) {
+	var result struct {
+		Age int
+	}
+
+	err := Reflect(Namespace{"age": "old"}, &result)
+	if err == nil {
+		t.Error("Expected an error reflecting a string into an int")
+	}
+}
@@ -0,0 +1,100 @@
+package artillery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ScriptOptions control how RunScript executes a script
+type ScriptOptions struct {
+	Name            string // Name of the script, used to prefix errors and to detect scripts which source themselves
+	ContinueOnError bool   // When true, execution continues past failed commands and every error is reported
+}
+
+// RunScript executes each command read from r, as though it had been entered into the shell.  Commands may
+// span several lines using the same quoting and trailing backslash rules as the shell, and blank lines and
+// comments are ignored.  Errors are prefixed with the script name and line number.  By default execution
+// stops at the first failed command, otherwise the errors of all failed commands are returned together.
+func (p *Processor) RunScript(r io.Reader, opts ScriptOptions) error {
+	defer p.beginRun(false)()
+	name := opts.Name
+	if name == "" {
+		name = "script"
+	}
+
+	if opts.Name != "" {
+		key := opts.Name
+		if abs, err := filepath.Abs(opts.Name); err == nil {
+			key = abs
+		}
+		for idx, source := range p.sources {
+			if source == key {
+				cycle := append(append([]string{}, p.sources[idx:]...), key)
+				return fmt.Errorf("Script cycle %s", strings.Join(cycle, " -> "))
+			}
+		}
+		defer func(sources []string) {
+			p.sources = sources
+		}(p.sources)
+		p.sources = append(p.sources[:len(p.sources):len(p.sources)], key)
+	}
+
+	failures := []string{}
+	reader := bufio.NewReader(r)
+	lines := []string{}
+	lineNumber := 0
+	startLine := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("%s:%d: %v", name, lineNumber+1, readErr)
+		}
+		if readErr == io.EOF && line == "" && len(lines) == 0 {
+			break
+		}
+
+		lineNumber++
+		if len(lines) == 0 {
+			startLine = lineNumber
+		}
+		lines = append(lines, strings.TrimRight(line, "\r\n"))
+		input := strings.Join(lines, "\n")
+		if readErr == nil && needsContinuation(input) {
+			continue
+		}
+		lines = lines[:0]
+
+		if len(lex(input).words) > 0 {
+			err := p.onExecute(nil, input, true)
+			if err != nil {
+				err = fmt.Errorf("%s:%d: %v", name, startLine, err)
+				if !opts.ContinueOnError {
+					return err
+				}
+				failures = append(failures, err.Error())
+			}
+		}
+
+		if readErr == io.EOF || p.hasExited() {
+			break
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// scriptPath resolves the path of a script relative to the directory of the script currently being run, or
+// the working directory when no script is running
+func (p *Processor) scriptPath(path string) string {
+	if filepath.IsAbs(path) || len(p.sources) == 0 {
+		return path
+	}
+	return filepath.Join(filepath.Dir(p.sources[len(p.sources)-1]), path)
+}
@@ -0,0 +1,275 @@
+package artillery
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testScript = `#!/usr/bin/env myapp
+# provision everything
+ok a
+
+ok 'b
+c' # multiple lines
+fail d
+ok e \
+  && ok f
+`
+
+func TestProcessorRunScript(t *testing.T) {
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommands(
+		&Command{
+			Name:        "ok",
+			Description: "always succeeds",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return nil
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return fmt.Errorf("failed")
+			},
+		},
+	)
+	err := processor.RunScript(strings.NewReader(testScript), ScriptOptions{Name: "setup.art"})
+	if err == nil || !strings.HasPrefix(err.Error(), "setup.art:7: ") {
+		t.Errorf("Expected an error on line 7, got %v", err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b\nc,d" {
+		t.Errorf("Expected calls %q, got %q", "a,b\nc,d", actual)
+	}
+
+	if processor.lastStatus != 1 {
+		t.Errorf("Expected the status of the failed command, got %d", processor.lastStatus)
+	}
+
+	calls = calls[:0]
+	err = processor.RunScript(strings.NewReader(testScript+"fail g"), ScriptOptions{ContinueOnError: true})
+	if err == nil || err.Error() != "script:7: failed\nscript:10: failed" {
+		t.Errorf("Expected errors on lines 7 and 10, got %v", err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b\nc,d,e,f,g" {
+		t.Errorf("Expected calls %q, got %q", "a,b\nc,d,e,f,g", actual)
+	}
+}
+
+func TestProcessorSource(t *testing.T) {
+	dir := t.TempDir()
+	outer := filepath.Join(dir, "outer.art")
+	inner := filepath.Join(dir, "inner.art")
+	cycle := filepath.Join(dir, "cycle.art")
+	back := filepath.Join(dir, "back.art")
+	os.WriteFile(outer, []byte("ok a\nsource "+quoteWord(inner)+"\nok c\n"), 0644)
+	os.WriteFile(inner, []byte("ok b\n"), 0644)
+	os.WriteFile(cycle, []byte("ok x\nsource "+quoteWord(back)+"\n"), 0644)
+	os.WriteFile(back, []byte("source "+quoteWord(cycle)+"\n"), 0644)
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "ok",
+		Description: "always succeeds",
+		Arguments: []*Argument{
+			{
+				Name:        "label",
+				Description: "label recorded on execution",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			calls = append(calls, ns["label"].(string))
+			return nil
+		},
+	})
+	err := processor.onExecute(nil, "source "+quoteWord(outer), true)
+	if err != nil {
+		t.Error(err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b,c" {
+		t.Errorf("Expected calls %q, got %q", "a,b,c", actual)
+	}
+
+	calls = calls[:0]
+	err = processor.onExecute(nil, "source "+quoteWord(cycle), true)
+	if err == nil || !strings.Contains(err.Error(), "Script cycle "+cycle+" -> "+back+" -> "+cycle) {
+		t.Errorf("Expected a cycle to be detected, got %v", err)
+	}
+	if actual := strings.Join(calls, ","); actual != "x" {
+		t.Errorf("Expected calls %q, got %q", "x", actual)
+	}
+
+	err = processor.onExecute(nil, "source "+quoteWord(filepath.Join(dir, "missing.art")), true)
+	if err == nil {
+		t.Error("Expected an error sourcing a missing file")
+	}
+	if len(processor.sources) != 0 {
+		t.Errorf("Expected no scripts to be running, got %v", processor.sources)
+	}
+}
+
+func TestProcessorSourceHint(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "unknown.art")
+	os.WriteFile(script, []byte("missing\n"), 0644)
+
+	stderr := &bytes.Buffer{}
+	processor := NewProcessor().withStreams(strings.NewReader(""), &bytes.Buffer{}, stderr)
+	processor.OnExecute(nil, "source "+quoteWord(script))
+	if count := strings.Count(stderr.String(), "for usage."); count != 1 {
+		t.Errorf("Expected the usage hint once, got %q", stderr.String())
+	}
+}
+
+func TestProcessorRunScriptExit(t *testing.T) {
+	dir := t.TempDir()
+	inner := filepath.Join(dir, "inner.art")
+	os.WriteFile(inner, []byte("ok b\nexit && ok c\nok d\n"), 0644)
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "ok",
+		Description: "always succeeds",
+		Arguments: []*Argument{
+			{
+				Name:        "label",
+				Description: "label recorded on execution",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			calls = append(calls, ns["label"].(string))
+			return nil
+		},
+	})
+	err := processor.RunScript(strings.NewReader("ok a\nsource "+quoteWord(inner)+"\nok e\n"), ScriptOptions{})
+	if err != nil {
+		t.Error(err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b" {
+		t.Errorf("Expected calls %q, got %q", "a,b", actual)
+	}
+
+	// Exiting only stops the run it happened in
+	calls = calls[:0]
+	processor.Process([]string{"exit"})
+	err = processor.Process([]string{"ok", "f"})
+	if err != nil {
+		t.Error(err)
+	}
+	err = processor.RunScript(strings.NewReader("ok g\n"), ScriptOptions{})
+	if err != nil {
+		t.Error(err)
+	}
+	if actual := strings.Join(calls, ","); actual != "f,g" {
+		t.Errorf("Expected calls %q, got %q", "f,g", actual)
+	}
+}
+
+func TestProcessorSourceRelative(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.art"), []byte("ok a\nsource lib/setup.art\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "lib", "setup.art"), []byte("source common.art\nok c\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "lib", "common.art"), []byte("ok b\n"), 0644)
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "ok",
+		Description: "always succeeds",
+		Arguments: []*Argument{
+			{
+				Name:        "label",
+				Description: "label recorded on execution",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			calls = append(calls, ns["label"].(string))
+			return nil
+		},
+	})
+	err := processor.onExecute(nil, "source "+quoteWord(filepath.Join(dir, "main.art")), true)
+	if err != nil {
+		t.Error(err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b,c" {
+		t.Errorf("Expected calls %q, got %q", "a,b,c", actual)
+	}
+}
+
+func TestProcessorRunPiped(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = stdin
+		r.Close()
+	}()
+
+	w.WriteString("ok a\nfail b\nok c\nexit\nok d\n")
+	w.Close()
+
+	calls := []string{}
+	processor := NewProcessor()
+	processor.AddCommands(
+		&Command{
+			Name:        "ok",
+			Description: "always succeeds",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return nil
+			},
+		},
+		&Command{
+			Name:        "fail",
+			Description: "always fails",
+			Arguments: []*Argument{
+				{
+					Name:        "label",
+					Description: "label recorded on execution",
+				},
+			},
+			OnExecute: func(ns Namespace, processor *Processor) error {
+				calls = append(calls, ns["label"].(string))
+				return fmt.Errorf("failed")
+			},
+		},
+	)
+	err = processor.Run()
+	if err == nil || err.Error() != "stdin:2: failed" {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+	if actual := strings.Join(calls, ","); actual != "a,b,c" {
+		t.Errorf("Expected calls %q, got %q", "a,b,c", actual)
+	}
+}
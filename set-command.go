@@ -40,13 +40,13 @@ func makeSetCommand() *Command {
 				if lower == "true" {
 					processor.nilShell.Debug = true
 					Debug = true
-					fmt.Println("debug mode on")
+					fmt.Fprintln(processor.Stdout(), "debug mode on")
 				} else if lower == "false" {
 					processor.nilShell.Debug = false
 					Debug = false
-					fmt.Println("debug mode off")
+					fmt.Fprintln(processor.Stdout(), "debug mode off")
 				} else {
-					tg.Println(tg.Red, "Debug setting must be true/false", tg.Reset)
+					tg.Fprintln(processor.Stderr(), tg.Red, "Debug setting must be true/false", tg.Reset)
 				}
 			}
 
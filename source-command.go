@@ -0,0 +1,48 @@
+package artillery
+
+import (
+	"os"
+)
+
+func makeSourceCommand() *Command {
+	return &Command{
+		Name:        "source",
+		Description: "execute the commands in a script file",
+		Options: []*Option{
+			{
+				ShortName:   'c',
+				Name:        "continue",
+				Description: "continue past failed commands",
+				Type:        Bool,
+				Value:       true,
+			},
+		},
+		Arguments: []*Argument{
+			{
+				Name:        "file",
+				Description: "script file to execute",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			var args struct {
+				File string
+			}
+			err := Reflect(ns, &args)
+			if err != nil {
+				return err
+			}
+
+			path := processor.scriptPath(args.File)
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			return processor.RunScript(f, ScriptOptions{
+				Name:            path,
+				ContinueOnError: ns["continue"] == true,
+			})
+		},
+	}
+}
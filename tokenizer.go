@@ -0,0 +1,289 @@
+package artillery
+
+import (
+	"strings"
+	"unicode"
+)
+
+// quoting identifies how a section of a word was quoted in the original input
+type quoting int8
+
+const (
+	unquoted quoting = iota
+	singleQuoted
+	doubleQuoted
+	escaped // A backslash escaped character, which is always literal
+)
+
+// wordPart is a run of characters within a word which share the same quoting
+type wordPart struct {
+	text    string
+	quoting quoting
+}
+
+// word is a single shell word, made up of one or more adjacent parts, or a control operator
+type word struct {
+	parts    []wordPart
+	operator string // Set when the word is an unquoted control operator such as ; or |
+	start    int    // Rune offset of the word within the input
+	end      int    // Rune offset immediately following the word
+}
+
+// String returns the literal value of the word, without any expansion
+func (w *word) String() string {
+	if w.operator != "" {
+		return w.operator
+	}
+
+	var sb strings.Builder
+	for _, part := range w.parts {
+		sb.WriteString(part.text)
+	}
+	return sb.String()
+}
+
+// lexResult describes the outcome of lexing a command string
+type lexResult struct {
+	words      []*word
+	openQuote  bool // The input ended inside of a quoted region
+	quoteChar  rune // The quote character of the unterminated region when openQuote is set
+	openEscape bool // The input ended with a backslash which has nothing to escape
+	inComment  bool // The input ended inside of a comment
+	inWord     bool // The input ended inside of a word, rather than on a word separator
+}
+
+// values returns the literal value of each word
+func (r *lexResult) values() []string {
+	values := make([]string, len(r.words))
+	for idx, w := range r.words {
+		values[idx] = w.String()
+	}
+	return values
+}
+
+// lexer breaks input into words following POSIX shell quoting rules
+type lexer struct {
+	input  []rune
+	pos    int
+	result *lexResult
+	word   *word
+}
+
+// lex breaks the command into words.  Unquoted whitespace separates words, a backslash escapes the
+// character which follows it, single quotes preserve everything literally, and double quotes preserve
+// everything except for backslash escapes of \, ", $, ` and newline.  Adjacent quoted and unquoted
+// segments are joined into a single word, so foo"bar baz" yields the word foobar baz.  The unquoted
+// control operators ;, &&, || and |, along with the redirection operators >, >>, 2> and 2>> are produced
+// as separate operator words.  An unquoted # at the start of a word begins a comment which runs to the
+// end of the line.
+func lex(cmd string) *lexResult {
+	l := &lexer{
+		input:  []rune(cmd),
+		result: &lexResult{words: []*word{}},
+	}
+	l.run()
+	return l.result
+}
+
+func (l *lexer) run() {
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case unicode.IsSpace(r):
+			l.endWord()
+			l.pos++
+		case r == '\\':
+			l.lexEscape()
+		case r == '\'':
+			l.lexSingleQuote()
+		case r == '"':
+			l.lexDoubleQuote()
+		case r == ';':
+			l.emitOperator(";")
+		case (r == '&' || r == '|') && l.peek(1) == r:
+			l.emitOperator(string([]rune{r, r}))
+		case r == '|':
+			l.emitOperator("|")
+		case r == '>' && l.peek(1) == '>':
+			l.emitOperator(">>")
+		case r == '>':
+			l.emitOperator(">")
+		case r == '#' && l.word == nil:
+			l.lexComment()
+		case r == '2' && l.word == nil && l.peek(1) == '>':
+			// Only a 2 which begins a word redirects the error stream, so that foo2>bar is still foo2 > bar
+			if l.peek(2) == '>' {
+				l.emitOperator("2>>")
+			} else {
+				l.emitOperator("2>")
+			}
+		default:
+			l.write(r, unquoted)
+			l.pos++
+		}
+	}
+	l.result.inWord = l.word != nil
+	l.endWord()
+}
+
+// peek returns the rune at the offset from the current position, or 0 beyond the end of input
+func (l *lexer) peek(offset int) rune {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+// emitOperator completes the current word and produces an operator word in its place
+func (l *lexer) emitOperator(operator string) {
+	l.endWord()
+	l.result.words = append(l.result.words, &word{
+		operator: operator,
+		start:    l.pos,
+		end:      l.pos + len([]rune(operator)),
+	})
+	l.pos += len([]rune(operator))
+}
+
+// startWord begins a new word at the current position, if one isn't already in progress
+func (l *lexer) startWord() {
+	l.startWordAt(l.pos)
+}
+
+// startWordAt begins a new word at the supplied position, if one isn't already in progress
+func (l *lexer) startWordAt(pos int) {
+	if l.word == nil {
+		l.word = &word{
+			parts: []wordPart{},
+			start: pos,
+		}
+	}
+}
+
+// write appends a rune to the current word, extending the final part when the quoting matches
+func (l *lexer) write(r rune, q quoting) {
+	l.startWord()
+	last := len(l.word.parts) - 1
+	if last >= 0 && l.word.parts[last].quoting == q {
+		l.word.parts[last].text += string(r)
+		return
+	}
+	l.word.parts = append(l.word.parts, wordPart{
+		text:    string(r),
+		quoting: q,
+	})
+}
+
+// endWord completes the word currently being assembled, if there is one
+func (l *lexer) endWord() {
+	if l.word == nil {
+		return
+	}
+	l.word.end = l.pos
+	l.result.words = append(l.result.words, l.word)
+	l.word = nil
+}
+
+// lexEscape handles an unquoted backslash
+func (l *lexer) lexEscape() {
+	start := l.pos
+	l.pos++
+	if l.pos >= len(l.input) {
+		// A trailing backslash has nothing to escape, so keep it literally
+		l.startWordAt(start)
+		l.write('\\', escaped)
+		l.result.openEscape = true
+		return
+	}
+
+	r := l.input[l.pos]
+	l.pos++
+	if r == '\n' {
+		// Line continuation, the backslash and newline are both removed
+		return
+	}
+	l.startWordAt(start)
+	l.write(r, escaped)
+}
+
+// lexComment discards everything up to the end of the line
+func (l *lexer) lexComment() {
+	for l.pos < len(l.input) {
+		if l.input[l.pos] == '\n' {
+			return
+		}
+		l.pos++
+	}
+	l.result.inComment = true
+}
+
+// lexSingleQuote consumes a single quoted region, in which every character is literal
+func (l *lexer) lexSingleQuote() {
+	l.startWord()
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		if r == '\'' {
+			return
+		}
+		l.write(r, singleQuoted)
+	}
+	l.result.openQuote = true
+	l.result.quoteChar = '\''
+}
+
+// lexDoubleQuote consumes a double quoted region, in which only a limited set of characters may be escaped
+func (l *lexer) lexDoubleQuote() {
+	l.startWord()
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		switch r {
+		case '"':
+			return
+		case '\\':
+			if l.pos < len(l.input) {
+				next := l.input[l.pos]
+				switch next {
+				case '\n':
+					l.pos++
+					continue
+				case '\\', '"', '$', '`':
+					l.pos++
+					l.write(next, escaped)
+					continue
+				}
+			}
+			l.write(r, doubleQuoted)
+		default:
+			l.write(r, doubleQuoted)
+		}
+	}
+	l.result.openQuote = true
+	l.result.quoteChar = '"'
+}
+
+// tokenize breaks the command into individual tokens, preserving quoted areas.  The second return value
+// is true when the command ends inside of an unterminated quotation.
+func tokenize(cmd string) ([]string, bool) {
+	result := lex(cmd)
+	return result.values(), result.openQuote
+}
+
+// unquotedSpecialChars are the characters which must be escaped to be taken literally outside of quotes
+const unquotedSpecialChars = "'\"\\$`;&|<>#"
+
+// quoteWord quotes the supplied value such that tokenize will reproduce it as a single, unaltered token
+func quoteWord(value string) string {
+	if value == "" {
+		return "''"
+	}
+
+	if !strings.ContainsAny(value, unquotedSpecialChars) && strings.IndexFunc(value, unicode.IsSpace) == -1 {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
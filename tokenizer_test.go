@@ -0,0 +1,117 @@
+package artillery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeTable(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		expected  []string
+		openQuote bool
+	}{
+		{"empty", "", []string{}, false},
+		{"whitespace only", " \t  ", []string{}, false},
+		{"simple", "list all animals", []string{"list", "all", "animals"}, false},
+		{"tabs", "list\tall\t\tanimals", []string{"list", "all", "animals"}, false},
+		{"unicode whitespace", "list all　animals", []string{"list", "all", "animals"}, false},
+		{"unicode runes", "add café ünïcødé", []string{"add", "café", "ünïcødé"}, false},
+		{"double quoted", `say "hello world"`, []string{"say", "hello world"}, false},
+		{"single quoted", `say 'hello world'`, []string{"say", "hello world"}, false},
+		{"adjacent segments", `foo"bar baz"`, []string{"foobar baz"}, false},
+		{"mixed adjacent segments", `a'b c'"d e"f`, []string{"ab cd ef"}, false},
+		{"quoted option value", `name="a b"`, []string{"name=a b"}, false},
+		{"escaped quotes in assignment", `name=\"a b\"`, []string{`name="a`, `b"`}, false},
+		{"escaped space", `my\ file.txt`, []string{"my file.txt"}, false},
+		{"escaped backslash", `a\\b`, []string{`a\b`}, false},
+		{"trailing backslash", `abc\`, []string{`abc\`}, false},
+		{"line continuation", "abc\\\ndef", []string{"abcdef"}, false},
+		{"empty double quotes", `say ""`, []string{"say", ""}, false},
+		{"empty single quotes", `say '' x`, []string{"say", "", "x"}, false},
+		{"single quotes are literal", `'a\"b $x'`, []string{`a\"b $x`}, false},
+		{"double quote escapes", `"a\"b \\ \$x \q"`, []string{`a"b \ $x \q`}, false},
+		{"double quote line continuation", "\"ab\\\ncd\"", []string{"abcd"}, false},
+		{"quotes inside other quotes", `"it's" 'say "hi"'`, []string{"it's", `say "hi"`}, false},
+		{"newline inside quotes", "\"a\nb\"", []string{"a\nb"}, false},
+		{"semicolon operator", "a;b ; c", []string{"a", ";", "b", ";", "c"}, false},
+		{"and or operators", "a&&b || c", []string{"a", "&&", "b", "||", "c"}, false},
+		{"quoted operators", `"a;b" 'c && d' e\;f`, []string{"a;b", "c && d", "e;f"}, false},
+		{"single ampersand is literal", "a & b", []string{"a", "&", "b"}, false},
+		{"pipe operator", "a | b|c", []string{"a", "|", "b", "|", "c"}, false},
+		{"redirect operators", "a > b>>c", []string{"a", ">", "b", ">>", "c"}, false},
+		{"stderr redirect", "a 2> b 2>>c", []string{"a", "2>", "b", "2>>", "c"}, false},
+		{"digit inside word", "a2>b", []string{"a2", ">", "b"}, false},
+		{"quoted redirect", `a '>' "2>" 2\>`, []string{"a", ">", "2>", "2>"}, false},
+		{"comment", "list all # show everything", []string{"list", "all"}, false},
+		{"comment only", "# nothing to see", []string{}, false},
+		{"comment after operator", "a;# b", []string{"a", ";"}, false},
+		{"comment ends at newline", "a # b\nc", []string{"a", "c"}, false},
+		{"quotes inside comment", "a # it's", []string{"a"}, false},
+		{"hash inside word", "a#b c#", []string{"a#b", "c#"}, false},
+		{"quoted hash", `'#a' "#b" \#c`, []string{"#a", "#b", "#c"}, false},
+		{"unterminated double", `say "hello`, []string{"say", "hello"}, true},
+		{"unterminated single", `say 'hello`, []string{"say", "hello"}, true},
+		{"mismatched quotes", `'quote mismatch"`, []string{`quote mismatch"`}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens, openQuote := tokenize(c.input)
+			if openQuote != c.openQuote {
+				t.Errorf("Expected openQuote %v, got %v", c.openQuote, openQuote)
+			}
+			if !reflect.DeepEqual(tokens, c.expected) {
+				t.Errorf("Expected\n%q\ngot\n%q", c.expected, tokens)
+			}
+		})
+	}
+}
+
+func TestLexInWord(t *testing.T) {
+	cases := []struct {
+		input  string
+		inWord bool
+	}{
+		{"", false},
+		{"help", true},
+		{"help ", false},
+		{`help\ `, true},
+		{`help ""`, true},
+		{"help\t", false},
+	}
+
+	for _, c := range cases {
+		if result := lex(c.input); result.inWord != c.inWord {
+			t.Errorf("Input %q expected inWord %v, got %v", c.input, c.inWord, result.inWord)
+		}
+	}
+}
+
+func TestQuoteWordRoundTrip(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		"has space",
+		"it's",
+		`say "hi"`,
+		`back\slash`,
+		"tab\there",
+		"$HOME",
+		"a'b\"c\\d e",
+		"ünï cødé",
+	}
+
+	for _, value := range values {
+		quoted := quoteWord(value)
+		tokens, openQuote := tokenize(quoted)
+		if openQuote {
+			t.Errorf("Quoted value %q produced an open quote", quoted)
+			continue
+		}
+		if len(tokens) != 1 || tokens[0] != value {
+			t.Errorf("Value %q quoted as %q tokenized to %q", value, quoted, tokens)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package artillery
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TypeParser converts a value supplied on the command line into a value of the type, returning an error
+// which describes the expected input when it can't
+type TypeParser func(value string) (any, error)
+
+// TypeOptions describe how a registered type is presented and handled
+type TypeOptions struct {
+	Display          string         // Name shown in help, defaults to the name of the type
+	Zero             any            // Any value of the type, which determines the element type of arrays ([]any when nil)
+	Complete         CompletionFunc // Suggests values for arguments of the type, unless the argument has its own completion
+	AcceptsDashValue bool           // When true, values beginning with a dash (ie. -5) are parsed as the type rather than as options
+}
+
+// registeredType is a type which may be used for arguments and options
+type registeredType struct {
+	name     ArgType
+	parser   TypeParser
+	options  TypeOptions
+	elemType reflect.Type
+}
+
+var typeRegistry = struct {
+	lock  sync.RWMutex
+	types map[ArgType]*registeredType
+}{
+	types: map[ArgType]*registeredType{},
+}
+
+func init() {
+	builtins := []struct {
+		name    ArgType
+		parser  TypeParser
+		options TypeOptions
+	}{
+		{String, parseString, TypeOptions{Zero: ""}},
+		{Int, parseInt, TypeOptions{Zero: 0, AcceptsDashValue: true}},
+		{Int64, parseInt64, TypeOptions{Zero: int64(0), AcceptsDashValue: true}},
+		{Uint, parseUint, TypeOptions{Zero: uint(0)}},
+		{Bytes, parseBytes, TypeOptions{Zero: int64(0)}},
+		{Float, parseFloat, TypeOptions{Zero: float64(0), AcceptsDashValue: true}},
+		{Bool, parseBool, TypeOptions{Zero: false, Complete: completeBool}},
+		{Duration, parseDuration, TypeOptions{Zero: time.Duration(0), AcceptsDashValue: true}},
+		{Time, parseTimeValue, TypeOptions{Zero: time.Time{}, AcceptsDashValue: true}},
+		{Path, parsePath, TypeOptions{Zero: "", Complete: completeAnyPath}},
+		{File, parsePath, TypeOptions{Zero: "", Complete: completeAnyPath}},
+		{Dir, parsePath, TypeOptions{Zero: "", Complete: completeDirPath}},
+		{IP, parseIP, TypeOptions{Zero: net.IP{}}},
+		{CIDR, parseCIDR, TypeOptions{Zero: &net.IPNet{}}},
+		{HostPort, parseHostPort, TypeOptions{Display: "host:port", Zero: HostAndPort{}}},
+		{URL, parseURL, TypeOptions{Zero: &url.URL{}}},
+		{JSON, parseJSON, TypeOptions{Complete: completeJSON}},
+	}
+	for _, builtin := range builtins {
+		options := builtin.options
+		err := RegisterType(builtin.name, builtin.parser, &options)
+		if err != nil {
+			panic(fmt.Sprintf("Problem registering the %s type\n%v", builtin.name, err))
+		}
+	}
+}
+
+// RegisterType makes a new type available to arguments and options, ie.
+//
+//	RegisterType("region", parseRegion, &TypeOptions{Zero: Region(""), Complete: completeRegion})
+//
+// Types are global, and must be registered before any command which uses them is added to a processor.
+func RegisterType(name ArgType, parser TypeParser, opts *TypeOptions) error {
+	if name == "" {
+		return fmt.Errorf("Type requires a name")
+	}
+	if parser == nil {
+		return fmt.Errorf("Type %s requires a parser", name)
+	}
+
+	rt := &registeredType{
+		name:     name,
+		parser:   parser,
+		elemType: reflect.TypeOf((*any)(nil)).Elem(),
+	}
+	if opts != nil {
+		rt.options = *opts
+	}
+	if rt.options.Display == "" {
+		rt.options.Display = string(name)
+	}
+	if rt.options.Zero != nil {
+		rt.elemType = reflect.TypeOf(rt.options.Zero)
+	}
+
+	typeRegistry.lock.Lock()
+	defer typeRegistry.lock.Unlock()
+	if _, exists := typeRegistry.types[name]; exists {
+		return fmt.Errorf("Type %s is already registered", name)
+	}
+	typeRegistry.types[name] = rt
+	return nil
+}
+
+// lookupType returns the registered type, where an empty name is a String
+func lookupType(name ArgType) (*registeredType, bool) {
+	if name == "" {
+		name = String
+	}
+	typeRegistry.lock.RLock()
+	defer typeRegistry.lock.RUnlock()
+	rt, ok := typeRegistry.types[name]
+	return rt, ok
+}
+
+// validateType returns an error when the type hasn't been registered
+func validateType(name ArgType) error {
+	if _, ok := lookupType(name); !ok {
+		return fmt.Errorf("Unknown type \"%s\"", name)
+	}
+	return nil
+}
+
+// typeDisplay returns the name of the type for display in help
+func typeDisplay(name ArgType) string {
+	if rt, ok := lookupType(name); ok {
+		return rt.options.Display
+	}
+	return string(name)
+}
+
+// appendValue appends a converted value to an array created by CreateEmptyArrayOfType
+func appendValue(array any, value any) (any, error) {
+	arr := reflect.ValueOf(array)
+	if arr.Kind() != reflect.Slice {
+		return array, nil
+	}
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || !val.Type().AssignableTo(arr.Type().Elem()) {
+		return nil, fmt.Errorf("parsed %T value cannot be stored in %T", value, array)
+	}
+	return reflect.Append(arr, val).Interface(), nil
+}
+
+// arrayLength returns the length of an array value, or 0 if the value isn't an array
+func arrayLength(array any) int {
+	arr := reflect.ValueOf(array)
+	if arr.Kind() != reflect.Slice {
+		return 0
+	}
+	return arr.Len()
+}
+
+func parseString(value string) (any, error) {
+	return value, nil
+}
+
+func parseFloat(value string) (any, error) {
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected a floating point value")
+	}
+	return val, nil
+}
+
+func parseBool(value string) (any, error) {
+	val := strings.ToLower(value)
+	if val == "true" {
+		return true, nil
+	}
+	if val == "false" {
+		return false, nil
+	}
+	return nil, fmt.Errorf("expected a boolean value")
+}
+
+func completeBool(prefix string, processor *Processor) []string {
+	values := []string{}
+	for _, value := range []string{"false", "true"} {
+		if strings.HasPrefix(value, strings.ToLower(prefix)) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseDuration(value string) (any, error) {
+	val, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected a duration such as 90s or 1h30m")
+	}
+	return val, nil
+}
+
+func parseTimeValue(value string) (any, error) {
+	val, err := parseTime(value, timeNow())
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func parsePath(value string) (any, error) {
+	if value == "" {
+		return nil, fmt.Errorf("expected a path")
+	}
+	return expandHome(value), nil
+}
+
+func completeAnyPath(prefix string, processor *Processor) []string {
+	return completeFiles(prefix, false)
+}
+
+func completeDirPath(prefix string, processor *Processor) []string {
+	return completeFiles(prefix, true)
+}
+
+// timeNow returns the time which relative time values are measured from
+var timeNow = time.Now
+
+// timeLayouts are the absolute formats accepted for the Time type, times without a zone are local
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses an absolute time, a named day (now, today, yesterday or tomorrow) or a duration relative
+// to now such as -2h
+func parseTime(value string, now time.Time) (time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if offset, err := time.ParseDuration(value); err == nil {
+		return now.Add(offset), nil
+	}
+
+	return time.Time{}, fmt.Errorf("expected a time such as 2006-01-02, 2006-01-02T15:04:05Z07:00, yesterday or -2h")
+}
@@ -0,0 +1,153 @@
+package artillery
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testRegion string
+
+var testRegions = []string{"eu-west", "us-east", "us-west"}
+
+func init() {
+	err := RegisterType("test-region", func(value string) (any, error) {
+		for _, region := range testRegions {
+			if value == region {
+				return testRegion(value), nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %s", strings.Join(testRegions, ", "))
+	}, &TypeOptions{
+		Display: "region",
+		Zero:    testRegion(""),
+		Complete: func(prefix string, processor *Processor) []string {
+			regions := []string{}
+			for _, region := range testRegions {
+				if strings.HasPrefix(region, prefix) {
+					regions = append(regions, region)
+				}
+			}
+			return regions
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = RegisterType("test-upper", func(value string) (any, error) {
+		return strings.ToUpper(value), nil
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	var result struct {
+		Regions []testRegion
+		Primary testRegion
+	}
+	cmd := Command{
+		Name:        "deploy",
+		Description: "deploy to regions",
+		Arguments: []*Argument{
+			{
+				Name:        "regions",
+				Description: "regions to deploy to",
+				Type:        "test-region",
+				IsArray:     true,
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "primary",
+				Description: "primary region",
+				Type:        "test-region",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return Reflect(ns, &result)
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tokens, err := parse("--primary=us-east eu-west us-west")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = cmd.Execute(tokens, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(result.Regions) != 2 || result.Regions[1] != "us-west" || result.Primary != "us-east" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	tokens, _ = parse("mars")
+	err = cmd.Execute(tokens, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "expected one of") {
+		t.Errorf("Expected the parser's error, got %v", err)
+	}
+
+	if display := cmd.Options[0].InvocationDisplay(); display != "--primary=<region>" {
+		t.Errorf("Expected display --primary=<region>, got %s", display)
+	}
+
+	sug := cmd.OnComplete([]any{"us"}, nil)
+	if len(sug) != 2 || sug[0].Name != "us-east" {
+		t.Errorf("Expected completion from the type, got %v", sug)
+	}
+}
+
+func TestRegisterTypeErrors(t *testing.T) {
+	if err := RegisterType(Int, parseInt, nil); err == nil {
+		t.Error("Expected an error registering a type twice")
+	}
+	if err := RegisterType("", parseInt, nil); err == nil {
+		t.Error("Expected an error registering a type without a name")
+	}
+	if err := RegisterType("test-noparser", nil, nil); err == nil {
+		t.Error("Expected an error registering a type without a parser")
+	}
+
+	cmd := &Command{
+		Name:        "broken",
+		Description: "uses an unknown type",
+		Arguments: []*Argument{
+			{
+				Name:        "value",
+				Description: "value of an unknown type",
+				Type:        "test-unknown",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	if err := cmd.Prepare(); err == nil {
+		t.Error("Expected an error preparing a command with an unknown type")
+	}
+}
+
+func TestRegisterTypeWithoutZero(t *testing.T) {
+	arr := CreateEmptyArrayOfType("test-upper")
+	arr, err := appendValue(arr, "A")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if values, ok := arr.([]any); !ok || len(values) != 1 || values[0] != "A" {
+		t.Errorf("Expected []any{\"A\"}, got %#v", arr)
+	}
+
+	if _, err = appendValue([]int{}, "A"); err == nil {
+		t.Error("Expected an error appending a value of the wrong type")
+	}
+}
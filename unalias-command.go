@@ -0,0 +1,59 @@
+package artillery
+
+import (
+	"strings"
+)
+
+func makeUnaliasCommand() *Command {
+	return &Command{
+		Name:        "unalias",
+		Description: "remove aliases",
+		Options: []*Option{
+			{
+				ShortName:   'a',
+				Name:        "all",
+				Description: "remove every alias",
+				Type:        Bool,
+				Value:       true,
+			},
+		},
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "name of the alias to remove",
+				IsArray:     true,
+				CompletionFunc: func(prefix string, processor *Processor) []string {
+					names := []string{}
+					for _, name := range processor.aliasNames() {
+						if strings.HasPrefix(name, prefix) {
+							names = append(names, name)
+						}
+					}
+					return names
+				},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			var args struct {
+				Name []string
+			}
+			err := Reflect(ns, &args)
+			if err != nil {
+				return err
+			}
+
+			names := args.Name
+			if ns["all"] == true {
+				names = processor.aliasNames()
+			}
+			for _, name := range names {
+				err = processor.RemoveAlias(name)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package artillery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashibuto/artillery/pkg/tg"
+)
+
+// ValidationError is returned by Execute when a value is rejected, either because it couldn't be converted,
+// broke a constraint or failed a ValidateFunc, or because the command's Validate function returned an error.
+// Token is the value as supplied on the command line, and is empty when the failure can't be attributed to a
+// single value.  The shell highlights the word which supplied the value.
+type ValidationError struct {
+	Token  string // Value which failed validation
+	Name   string // Name of the argument or option, empty for command validation
+	Err    error
+	prefix string
+	index  int // Index of the word which supplied the value, see argInput
+}
+
+func (e *ValidationError) Error() string {
+	if e.prefix == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s - %s", e.prefix, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateValue applies the constraints and validation function to a converted value
+func validateValue(value any, constraints *valueConstraints, validateFunc func(any) error) error {
+	err := constraints.check(value)
+	if err != nil {
+		return err
+	}
+	if validateFunc != nil {
+		return validateFunc(value)
+	}
+	return nil
+}
+
+// asValidationError returns the error of a command's Validate function as a *ValidationError
+func asValidationError(err error) error {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return err
+	}
+	return &ValidationError{Err: err}
+}
+
+// atWord attributes a validation error to the word which supplied the rejected value
+func atWord(err error, index int) error {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		validationErr.index = index
+	}
+	return err
+}
+
+// highlightToken renders the command with the word which supplied the rejected value highlighted, returning
+// false when the error isn't attributed to a word of the command
+func highlightToken(words []string, err error) (string, bool) {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.index <= 0 || validationErr.index >= len(words) {
+		return "", false
+	}
+
+	display := make([]string, len(words))
+	for idx, w := range words {
+		display[idx] = quoteWord(w)
+	}
+	display[validationErr.index] = tg.Underline + tg.Red + display[validationErr.index] + tg.Reset
+	return strings.Join(display, " "), true
+}
@@ -0,0 +1,230 @@
+package artillery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashibuto/artillery/pkg/tg"
+)
+
+var errTaken = errors.New("name is taken")
+
+func TestCommandValidation(t *testing.T) {
+	cmd := Command{
+		Name:        "create",
+		Description: "create a project",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "project name",
+				MemberOf:    []string{"alpha", "admin", "beta"},
+				ValidateFunc: func(value any) error {
+					if value == "admin" {
+						return errTaken
+					}
+					return nil
+				},
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "port",
+				Description: "port to serve on",
+				Type:        Int,
+				ValidateFunc: func(value any) error {
+					if value.(int)%2 != 0 {
+						return fmt.Errorf("must be even")
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "replicas",
+				Description: "number of replicas",
+				Type:        Int,
+				Default:     1,
+				Max:         10,
+			},
+		},
+		Validate: func(ns Namespace) error {
+			if ns["name"] == "beta" && ns["port"] != nil {
+				return fmt.Errorf("beta projects choose their own port")
+			}
+			if ns["replicas"] == 7 {
+				return &ValidationError{Token: "7", Name: "replicas", Err: fmt.Errorf("unlucky")}
+			}
+			return nil
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		input string
+		token string
+		name  string
+		err   string
+	}{
+		{"--port=80 alpha", "", "", ""},
+		{"admin", "admin", "name", "Argument name - name is taken"},
+		{"--port 81 alpha", "81", "port", "Option --port=<int> - must be even"},
+		{"--port=eighty alpha", "eighty", "port", "Option --port=<int> - expected an integer value"},
+		{"--replicas=11 alpha", "11", "replicas", "Option --replicas=1 - must be at most 10"},
+		{"--port=80 beta", "", "", "beta projects choose their own port"},
+		{"--replicas=7 alpha", "7", "replicas", "unlucky"},
+	}
+
+	for _, c := range cases {
+		tokens, err := parse(c.input)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = cmd.Execute(tokens, nil, false)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("Input %q unexpected error %v", c.input, err)
+			}
+			continue
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Input %q expected a *ValidationError, got %v", c.input, err)
+			continue
+		}
+		if validationErr.Token != c.token || validationErr.Name != c.name || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("Input %q expected %q from %q of %s, got %q from %q of %s", c.input, c.err, c.token, c.name, err, validationErr.Token, validationErr.Name)
+		}
+	}
+
+	tokens, _ := parse("admin")
+	if err := cmd.Execute(tokens, nil, false); !errors.Is(err, errTaken) {
+		t.Errorf("Expected the validation error to wrap errTaken, got %v", err)
+	}
+}
+
+func TestValidationCompletion(t *testing.T) {
+	cmd := Command{
+		Name:        "create",
+		Description: "create a project",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "project name",
+				MemberOf:    []string{"alpha", "admin", "beta"},
+				ValidateFunc: func(value any) error {
+					if value == "admin" {
+						return errTaken
+					}
+					return nil
+				},
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	}
+	err := cmd.Prepare()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sug := cmd.OnComplete([]any{"a"}, nil)
+	if len(sug) != 1 || sug[0].Name != "alpha" {
+		t.Errorf("Expected only alpha to be suggested, got %d suggestions", len(sug))
+	}
+}
+
+func TestValidationHighlight(t *testing.T) {
+	highlighted := tg.Underline + tg.Red + "%s" + tg.Reset
+	cases := []struct {
+		words    []string
+		err      error
+		expected string
+	}{
+		{[]string{"create", "--port", "81", "alpha"}, &ValidationError{Token: "81", index: 2}, "create --port " + fmt.Sprintf(highlighted, "81") + " alpha"},
+		{[]string{"create", "--port=81", "alpha"}, &ValidationError{Token: "81", index: 1}, "create " + fmt.Sprintf(highlighted, "--port=81") + " alpha"},
+		{[]string{"create", "my project"}, &ValidationError{Token: "my project", index: 1}, "create " + fmt.Sprintf(highlighted, "'my project'")},
+		{[]string{"create", "alpha"}, &ValidationError{Token: "alpha"}, ""},
+		{[]string{"create", "alpha"}, &ValidationError{Err: fmt.Errorf("failed")}, ""},
+		{[]string{"create", "alpha"}, fmt.Errorf("failed"), ""},
+	}
+	for _, c := range cases {
+		line, ok := highlightToken(c.words, c.err)
+		if ok != (c.expected != "") || line != c.expected {
+			t.Errorf("Words %v expected %q, got %q", c.words, c.expected, line)
+		}
+	}
+
+	// The word which supplied the value is highlighted, rather than the first word with the same value
+	inputs := map[string]string{
+		"create --replicas 3 --port 3 alpha": "create --replicas 3 --port " + fmt.Sprintf(highlighted, "3") + " alpha",
+		"create --port 4 -- admin":           "create --port 4 -- " + fmt.Sprintf(highlighted, "admin"),
+	}
+	processor := NewProcessor()
+	processor.AddCommand(&Command{
+		Name:        "create",
+		Description: "create a project",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "project name",
+				MemberOf:    []string{"alpha", "admin", "beta"},
+				ValidateFunc: func(value any) error {
+					if value == "admin" {
+						return errTaken
+					}
+					return nil
+				},
+			},
+		},
+		Options: []*Option{
+			{
+				Name:        "port",
+				Description: "port to serve on",
+				Type:        Int,
+				ValidateFunc: func(value any) error {
+					if value.(int)%2 != 0 {
+						return fmt.Errorf("must be even")
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "replicas",
+				Description: "number of replicas",
+				Type:        Int,
+				Default:     1,
+				Max:         10,
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			return nil
+		},
+	})
+	for input, expected := range inputs {
+		stderr := &bytes.Buffer{}
+		processor.withStreams(nil, stderr, stderr).onExecute(processor.Shell(), input, false)
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("Input %q expected the value to be highlighted\n%s", input, stderr.String())
+		}
+	}
+
+	// Only the interactive shell highlights
+	stderr := &bytes.Buffer{}
+	processor.withStreams(nil, stderr, stderr).onExecute(nil, "create --port 81 alpha", false)
+	if strings.Contains(stderr.String(), tg.Underline) {
+		t.Errorf("Unexpected highlight\n%s", stderr.String())
+	}
+}
@@ -0,0 +1,203 @@
+package artillery
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	ns "github.com/hashibuto/nilshell"
+)
+
+var validVariableName = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// variableStore holds named values such as shell variables and aliases.  It's shared by a processor and the
+// per-invocation copies handed to commands, which may run concurrently within a pipeline.
+type variableStore struct {
+	lock   sync.RWMutex
+	values map[string]string
+}
+
+func newVariableStore() *variableStore {
+	return &variableStore{
+		values: map[string]string{},
+	}
+}
+
+func (vs *variableStore) get(name string) (string, bool) {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+	value, ok := vs.values[name]
+	return value, ok
+}
+
+func (vs *variableStore) set(name string, value string) {
+	vs.lock.Lock()
+	defer vs.lock.Unlock()
+	vs.values[name] = value
+}
+
+// remove deletes the named value, returning false if it didn't exist
+func (vs *variableStore) remove(name string) bool {
+	vs.lock.Lock()
+	defer vs.lock.Unlock()
+	_, ok := vs.values[name]
+	delete(vs.values, name)
+	return ok
+}
+
+// names returns the sorted names of all variables
+func (vs *variableStore) names() []string {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+	names := []string{}
+	for name := range vs.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetVariable sets a shell variable which can be referenced as $name or ${name} in subsequent input
+func (p *Processor) SetVariable(name string, value string) error {
+	if !validVariableName.MatchString(name) {
+		return fmt.Errorf("Invalid variable name \"%s\", names may only contain A-Z, a-z, 0-9 and _ and cannot begin with a digit", name)
+	}
+	if p.variables == nil {
+		p.variables = newVariableStore()
+	}
+	p.variables.set(name, value)
+	return nil
+}
+
+// Variable returns the value of a shell variable, falling back on the environment when the processor
+// has no such variable
+func (p *Processor) Variable(name string) (string, bool) {
+	if name == "?" {
+		return strconv.Itoa(p.status()), true
+	}
+	if p.variables != nil {
+		if value, ok := p.variables.get(name); ok {
+			return value, true
+		}
+	}
+	return os.LookupEnv(name)
+}
+
+// expandWords expands variable references in each word, returning the final token values
+func (p *Processor) expandWords(words []*word) []string {
+	lookup := func(name string) string {
+		value, _ := p.Variable(name)
+		return value
+	}
+
+	values := make([]string, len(words))
+	for idx, w := range words {
+		values[idx] = expandWord(w, lookup)
+	}
+	return values
+}
+
+// completeVariable suggests variable names for a token beginning with $
+func (p *Processor) completeVariable(prefix string) []*ns.AutoComplete {
+	braced := strings.HasPrefix(prefix, "${")
+	var partial string
+	if braced {
+		partial = prefix[2:]
+	} else {
+		partial = prefix[1:]
+	}
+
+	names := map[string]struct{}{}
+	if p.variables != nil {
+		for _, name := range p.variables.names() {
+			names[name] = struct{}{}
+		}
+	}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		names[name] = struct{}{}
+	}
+
+	sug := []*ns.AutoComplete{}
+	for name := range names {
+		if !strings.HasPrefix(name, partial) || !validVariableName.MatchString(name) {
+			continue
+		}
+		if braced {
+			name = "${" + name + "}"
+		} else {
+			name = "$" + name
+		}
+		sug = append(sug, &ns.AutoComplete{
+			Name: name,
+		})
+	}
+
+	sort.Slice(sug, func(i, j int) bool {
+		return sug[i].Name < sug[j].Name
+	})
+	return sug
+}
+
+// expandWord produces the value of a word, expanding $name, ${name} and $? references within the unquoted
+// and double quoted parts.  Single quoted and backslash escaped parts are always literal.
+func expandWord(w *word, lookup func(name string) string) string {
+	var sb strings.Builder
+	for _, part := range w.parts {
+		if part.quoting == unquoted || part.quoting == doubleQuoted {
+			sb.WriteString(expandText(part.text, lookup))
+		} else {
+			sb.WriteString(part.text)
+		}
+	}
+	return sb.String()
+}
+
+// expandText expands variable references within the text
+func expandText(text string, lookup func(name string) string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '$' || i == len(text)-1 {
+			sb.WriteByte(c)
+			continue
+		}
+
+		next := text[i+1]
+		switch {
+		case next == '?':
+			sb.WriteString(lookup("?"))
+			i++
+		case next == '{':
+			end := strings.IndexByte(text[i+2:], '}')
+			if end == -1 || !validVariableName.MatchString(text[i+2:i+2+end]) {
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteString(lookup(text[i+2 : i+2+end]))
+			i += end + 2
+		case isVariableStart(next):
+			j := i + 1
+			for j < len(text) && isVariableChar(text[j]) {
+				j++
+			}
+			sb.WriteString(lookup(text[i+1 : j]))
+			i = j - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func isVariableStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isVariableChar(c byte) bool {
+	return isVariableStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,121 @@
+package artillery
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExpandWord(t *testing.T) {
+	vars := map[string]string{
+		"name":  "tiger",
+		"empty": "",
+		"?":     "1",
+	}
+	lookup := func(name string) string {
+		return vars[name]
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"$name", "tiger"},
+		{"${name}s", "tigers"},
+		{"$names", ""},
+		{`"$name and $name"`, "tiger and tiger"},
+		{"'$name'", "$name"},
+		{`\$name`, "$name"},
+		{`"\$name"`, "$name"},
+		{"$?", "1"},
+		{"a$empty-b", "a-b"},
+		{"$", "$"},
+		{"cost$5", "cost$5"},
+		{"${unterminated", "${unterminated"},
+		{`"${bad name}"`, "${bad name}"},
+		{"pre'$name'$name", "pre$nametiger"},
+	}
+
+	for _, c := range cases {
+		lexed := lex(c.input)
+		if len(lexed.words) != 1 {
+			t.Errorf("Input %q expected 1 word, got %d", c.input, len(lexed.words))
+			continue
+		}
+		if actual := expandWord(lexed.words[0], lookup); actual != c.expected {
+			t.Errorf("Input %q expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestProcessorVariables(t *testing.T) {
+	var received string
+	processor := NewProcessor()
+	err := processor.AddCommand(&Command{
+		Name:        "greet",
+		Description: "greet someone",
+		Arguments: []*Argument{
+			{
+				Name:        "name",
+				Description: "who to greet",
+			},
+		},
+		OnExecute: func(ns Namespace, processor *Processor) error {
+			received = ns["name"].(string)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = processor.onExecute(nil, `let who="big cat"`, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = processor.onExecute(nil, "greet $who", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if received != "big cat" {
+		t.Errorf("Expected \"big cat\", got %q", received)
+	}
+
+	os.Setenv("ARTILLERY_TEST_VAR", "from env")
+	defer os.Unsetenv("ARTILLERY_TEST_VAR")
+	err = processor.onExecute(nil, "greet \"${ARTILLERY_TEST_VAR}\"", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if received != "from env" {
+		t.Errorf("Expected environment fallback, got %q", received)
+	}
+
+	processor.onExecute(nil, "unknown", true)
+	err = processor.onExecute(nil, "greet $?", true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if received != "1" {
+		t.Errorf("Expected last status of 1, got %q", received)
+	}
+
+	err = processor.Process([]string{"greet", "$who"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if received != "$who" {
+		t.Errorf("Expected cli arguments to remain literal, got %q", received)
+	}
+
+	err = processor.onExecute(nil, "let 1abc=3", true)
+	if err == nil {
+		t.Errorf("Expected an invalid variable name to be rejected")
+	}
+}